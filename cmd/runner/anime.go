@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"kanbanchan/internal/notion"
+	"sort"
+)
+
+// syncAnimeCommand plans syncing the AniList watching, completed and planning
+// lists to the Anime DB, applying the plan when -apply is given
+func (c *clients) syncAnimeCommand(args []string) error {
+	flags := flag.NewFlagSet("sync anime", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectAniList()
+	if err != nil {
+		return err
+	}
+	plan, err := c.planAnime()
+	if err != nil {
+		return err
+	}
+	return c.finishPlan(plan, *apply)
+}
+
+// planAnime plans adding every anime on the synced AniList lists that's
+// missing from the Anime DB and bringing the rest up to date
+func (c *clients) planAnime() (*notion.Plan, error) {
+	list, err := c.aniListClient.GetAnimeList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anilist anime: %s", err.Error())
+	}
+	c.logf("retrieved %d anime from anilist", len(list))
+
+	pages, err := c.notionClient.ListAnimePages(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notion anime: %s", err.Error())
+	}
+	index := notion.NewAnimeIndex(pages)
+
+	ids := make([]string, 0, len(list))
+	for id := range list {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	plan := &notion.Plan{}
+	for _, id := range ids {
+		anime := list[id]
+		existing, ok := index.Get(anime)
+		if !ok {
+			op, err := c.notionClient.PlanAddAnime(anime)
+			if err != nil {
+				return nil, err
+			}
+			plan.Add(op)
+			continue
+		}
+
+		op, err := c.notionClient.PlanAnimeSync(existing, anime)
+		if err != nil {
+			return nil, err
+		}
+		plan.Add(op)
+	}
+	return plan, nil
+}
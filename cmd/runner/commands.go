@@ -0,0 +1,561 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"kanbanchan/internal/aws"
+	"kanbanchan/internal/notion"
+	"kanbanchan/internal/steam"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jomei/notionapi"
+)
+
+// lookupMatches is how many Steam apps lookup lists for a name
+const lookupMatches = 5
+
+var appIDPattern = regexp.MustCompile(`^\d+$`)
+
+// syncGamesCommand plans syncing the Steam library and wishlist to the Games
+// DB, applying the plan when -apply is given
+func (c *clients) syncGamesCommand(args []string) error {
+	flags := flag.NewFlagSet("sync games", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	backfillAppIDs := flags.Bool("backfill-app-ids", false, "plan linking existing game pages to their Steam app IDs instead of syncing")
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectSteam()
+	if err != nil {
+		return err
+	}
+
+	if *backfillAppIDs {
+		plan, err := c.planBackfillSteamAppIDs()
+		if err != nil {
+			return err
+		}
+		return c.finishPlan(plan, *apply)
+	}
+
+	plan, partial, err := c.planGames()
+	if err != nil {
+		return err
+	}
+	c.partial = partial
+	if c.config.Steam.RecentlyPlayed.Enabled {
+		activityPlan, err := c.planRecentlyPlayed()
+		if err != nil {
+			return err
+		}
+		plan.Merge(activityPlan)
+	}
+	return c.finishPlan(plan, *apply)
+}
+
+// transitionCommand plans moving games between statuses by the configured
+// transition rules, applying the plan when -apply is given
+func (c *clients) transitionCommand(args []string) error {
+	flags := flag.NewFlagSet("transition", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	plan, err := c.planTransitions()
+	if err != nil {
+		return err
+	}
+	return c.finishPlan(plan, *apply)
+}
+
+// finishPlan prints a plan and applies it when asked to
+func (c *clients) finishPlan(plan *notion.Plan, apply bool) error {
+	var err error
+	if c.options.output == "json" {
+		err = plan.WriteJSON(os.Stdout)
+	} else {
+		err = plan.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to print plan: %s", err.Error())
+	}
+
+	if !apply {
+		if len(plan.Operations) > 0 {
+			c.warnf("dry run, run with -apply to make these changes")
+		}
+		return nil
+	}
+	c.logf("applying %d operation(s)", len(plan.Operations))
+	return c.notionClient.ApplyPlan(plan)
+}
+
+// statusCommand counts the pages in the Games DB by status
+func (c *clients) statusCommand(args []string) error {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	games, err := c.notionClient.ListGamePages(nil)
+	if err != nil {
+		return err
+	}
+
+	status := struct {
+		Environment string         `json:"environment"`
+		Total       int            `json:"total"`
+		Statuses    map[string]int `json:"statuses"`
+		Unlinked    int            `json:"unlinked"`
+	}{
+		Environment: c.options.env,
+		Total:       len(games),
+		Statuses:    make(map[string]int),
+		Unlinked:    len(notion.NewGameIndex(games).Unlinked()),
+	}
+	for _, game := range games {
+		name := "<none>"
+		if game.Status != nil && game.Status.Status.Name != "" {
+			name = game.Status.Status.Name
+		}
+		status.Statuses[name]++
+	}
+
+	return c.writeOutput(status, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Environment:\t%s\n", status.Environment)
+		for _, name := range sortedKeys(status.Statuses) {
+			fmt.Fprintf(tw, "%s:\t%d\n", name, status.Statuses[name])
+		}
+		fmt.Fprintf(tw, "Total:\t%d\n", status.Total)
+		fmt.Fprintf(tw, "Without Steam app ID:\t%d\n", status.Unlinked)
+	})
+}
+
+// lookupResult is a Steam app found by lookup, along with its page on the board
+type lookupResult struct {
+	AppID       string  `json:"appID"`
+	Name        string  `json:"name"`
+	Score       float64 `json:"score,omitempty"`
+	StorePage   string  `json:"storePage"`
+	BoardTitle  string  `json:"boardTitle,omitempty"`
+	BoardStatus string  `json:"boardStatus,omitempty"`
+}
+
+// lookupCommand finds a game on Steam by app ID or name and shows whether
+// it's already on the board
+func (c *clients) lookupCommand(args []string) error {
+	query := strings.TrimSpace(strings.Join(args, " "))
+	if query == "" {
+		return usageError{"lookup needs a game name or Steam app ID"}
+	}
+
+	err := c.connectSteam()
+	if err != nil {
+		return err
+	}
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+
+	var results []lookupResult
+	if appIDPattern.MatchString(query) {
+		app, err := c.steamClient.GetApp(query)
+		if err != nil {
+			return fmt.Errorf("failed to get steam app id %s: %s", query, err.Error())
+		}
+		if !app.Success {
+			return fmt.Errorf("steam has no app with id %s", query)
+		}
+		results = append(results, lookupResult{AppID: query, Name: app.Data.Name})
+	} else {
+		matches, err := c.steamClient.SearchApps(query, lookupMatches)
+		if err != nil {
+			return fmt.Errorf("failed to search steam apps for \"%s\": %s", query, err.Error())
+		}
+		for _, match := range matches {
+			results = append(results, lookupResult{AppID: strconv.Itoa(match.AppID), Name: match.Name, Score: match.Score})
+		}
+	}
+
+	pages, err := c.notionClient.ListGamePages(nil)
+	if err != nil {
+		return err
+	}
+	index := notion.NewGameIndex(pages)
+	for i := range results {
+		results[i].StorePage = fmt.Sprintf("https://store.steampowered.com/app/%s", results[i].AppID)
+		if page, ok := index.Get(notion.PlatformSteam, results[i].AppID); ok {
+			results[i].BoardTitle = page.Title()
+			if page.Status != nil {
+				results[i].BoardStatus = page.Status.Status.Name
+			}
+		}
+	}
+
+	return c.writeOutput(results, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "APP ID\tNAME\tSCORE\tON BOARD\tSTORE PAGE")
+		for _, result := range results {
+			score := ""
+			if result.Score > 0 {
+				score = strconv.FormatFloat(result.Score, 'f', 2, 64)
+			}
+			onBoard := "no"
+			if result.BoardTitle != "" {
+				onBoard = fmt.Sprintf("%s (%s)", result.BoardTitle, result.BoardStatus)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", result.AppID, result.Name, score, onBoard, result.StorePage)
+		}
+	})
+}
+
+// steamShortcutsCommand lists the non-Steam games added to the local Steam client
+func (c *clients) steamShortcutsCommand(args []string) error {
+	flags := flag.NewFlagSet("steam shortcuts", flag.ContinueOnError)
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectSteam()
+	if err != nil {
+		return err
+	}
+	shortcuts, err := c.steamClient.GetShortcuts()
+	if err != nil {
+		return err
+	}
+
+	return c.writeOutput(shortcuts, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "APP ID\tNAME\tLAST PLAYED\tTAGS")
+		for _, shortcut := range shortcuts {
+			lastPlayed := "never"
+			if !shortcut.LastPlayed.IsZero() {
+				lastPlayed = shortcut.LastPlayed.In(c.clock.Location()).Format("2006-01-02")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", shortcut.AppID, shortcut.Name, lastPlayed, strings.Join(shortcut.Tags, ", "))
+		}
+	})
+}
+
+// steamPlaytimeCommand lists the playtime the local Steam client has
+// recorded, including offline sessions the Web API hasn't seen yet
+func (c *clients) steamPlaytimeCommand(args []string) error {
+	flags := flag.NewFlagSet("steam playtime", flag.ContinueOnError)
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectSteam()
+	if err != nil {
+		return err
+	}
+	playtimes, err := c.steamClient.GetLocalPlaytime()
+	if err != nil {
+		return err
+	}
+
+	appIDs := make([]string, 0, len(playtimes))
+	for appID := range playtimes {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Strings(appIDs)
+	results := make([]steam.LocalPlaytime, 0, len(appIDs))
+	for _, appID := range appIDs {
+		results = append(results, playtimes[appID])
+	}
+
+	return c.writeOutput(results, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "APP ID\tPLAYTIME\tLAST 2 WEEKS\tLAST PLAYED")
+		for _, playtime := range results {
+			lastPlayed := "never"
+			if !playtime.LastPlayed.IsZero() {
+				lastPlayed = playtime.LastPlayed.In(c.clock.Location()).Format("2006-01-02")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", playtime.AppID, playtime.Playtime, playtime.RecentPlaytime, lastPlayed)
+		}
+	})
+}
+
+// schemaProperty is a single property of a database
+type schemaProperty struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Options []string `json:"options,omitempty"`
+}
+
+// dbSchemaCommand lists the properties of the Games DB
+func (c *clients) dbSchemaCommand(args []string) error {
+	flags := flag.NewFlagSet("db schema", flag.ContinueOnError)
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	db, err := c.notionClient.GetGameDatabase()
+	if err != nil {
+		return err
+	}
+
+	var properties []schemaProperty
+	for name, config := range db.Properties {
+		property := schemaProperty{Name: name, Type: string(notion.PropertyType(config))}
+		var options []notionapi.Option
+		switch config := config.(type) {
+		case *notionapi.SelectPropertyConfig:
+			options = config.Select.Options
+		case *notionapi.MultiSelectPropertyConfig:
+			options = config.MultiSelect.Options
+		}
+		for _, option := range options {
+			property.Options = append(property.Options, option.Name)
+		}
+		properties = append(properties, property)
+	}
+	sort.Slice(properties, func(a, b int) bool {
+		return properties[a].Name < properties[b].Name
+	})
+
+	return c.writeOutput(properties, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "PROPERTY\tTYPE\tOPTIONS")
+		for _, property := range properties {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", property.Name, property.Type, strings.Join(property.Options, ", "))
+		}
+	})
+}
+
+// dbVerifyCommand reports how a database differs from the properties, types
+// and options kanbanchan expects, failing when it differs at all
+func (c *clients) dbVerifyCommand(args []string) error {
+	kind, _, err := parseKindArgs("db verify", args, false)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	problems, err := c.notionClient.VerifySchema(kind)
+	if err != nil {
+		return err
+	}
+	return c.reportSchemaProblems(kind, problems)
+}
+
+// dbProvisionCommand lists the properties and select options a database is
+// missing, adding them when -apply is given. Problems that can only be fixed
+// in Notion, like missing status options, are reported either way
+func (c *clients) dbProvisionCommand(args []string) error {
+	kind, apply, err := parseKindArgs("db provision", args, true)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	var problems []notion.SchemaProblem
+	if apply {
+		problems, err = c.notionClient.ProvisionSchema(kind)
+	} else {
+		problems, err = c.notionClient.VerifySchema(kind)
+	}
+	if err != nil {
+		return err
+	}
+	if !apply {
+		for _, problem := range problems {
+			if problem.Fixable {
+				fmt.Fprintln(os.Stderr, "dry run, pass -apply to add the missing properties and options")
+				break
+			}
+		}
+	}
+	return c.reportSchemaProblems(kind, problems)
+}
+
+// parseKindArgs parses the flags of a db subcommand followed by an optional
+// kind of media, which defaults to games. -apply is only accepted when withApply
+func parseKindArgs(name string, args []string, withApply bool) (*notion.MediaKind, bool, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	var apply *bool
+	if withApply {
+		apply = flags.Bool("apply", false, "make the changes in Notion instead of only listing them")
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, false, usageError{err.Error()}
+	}
+	if flags.NArg() > 1 {
+		return nil, false, usageError{fmt.Sprintf("unexpected arguments: %s", strings.Join(flags.Args()[1:], " "))}
+	}
+
+	kind := notion.Games
+	if flags.NArg() == 1 {
+		var ok bool
+		kind, ok = notion.LookupMediaKind(flags.Arg(0))
+		if !ok {
+			return nil, false, usageError{fmt.Sprintf("unknown database %s, expected games, anime, movies or tv", flags.Arg(0))}
+		}
+	}
+	return kind, apply != nil && *apply, nil
+}
+
+// reportSchemaProblems writes the problems found with a database, returning
+// an error when there are any
+func (c *clients) reportSchemaProblems(kind *notion.MediaKind, problems []notion.SchemaProblem) error {
+	if problems == nil {
+		problems = []notion.SchemaProblem{}
+	}
+	err := c.writeOutput(problems, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "FIELD\tPROPERTY\tPROBLEM\tEXPECTED\tACTUAL\tFIX")
+		for _, problem := range problems {
+			fix := "in notion"
+			if problem.Fixable {
+				fix = "db provision"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", problem.Field, problem.Property, problem.Problem, problem.Expected, problem.Actual, fix)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("the %s database has %d schema problems", kind.Plural, len(problems))
+	}
+	return nil
+}
+
+// secretCheck is the result of checking a single secret
+type secretCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// secretsCheckCommand checks that every secret the runner needs is set and
+// that the Notion and Steam credentials work, without printing any secrets
+func (c *clients) secretsCheckCommand(args []string) error {
+	flags := flag.NewFlagSet("secrets check", flag.ContinueOnError)
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := aws.GetSecrets()
+	if err != nil {
+		return fmt.Errorf("failed to read secrets from %s: %s", aws.SecretsPath, err.Error())
+	}
+
+	gameDB, gameDBName := secrets.Notion.GameDB, "notion.gameDB"
+	if notion.UsesTestDatabases(c.options.env) {
+		gameDB, gameDBName = secrets.Notion.TestGame, "notion.testGame"
+	}
+	checks := []secretCheck{
+		presenceCheck("notion.authToken", secrets.Notion.AuthToken),
+		presenceCheck(gameDBName, gameDB),
+		presenceCheck("steam.id", secrets.Steam.ID),
+		presenceCheck("steam.key", secrets.Steam.Key),
+	}
+
+	notionCheck := secretCheck{Name: "notion access", OK: true}
+	err = c.connectNotion()
+	if err == nil {
+		_, err = c.notionClient.GetGameDatabase()
+	}
+	if err != nil {
+		notionCheck.OK, notionCheck.Detail = false, err.Error()
+	}
+	checks = append(checks, notionCheck)
+
+	steamCheck := secretCheck{Name: "steam access", OK: true}
+	err = c.connectSteam()
+	if err == nil {
+		err = c.steamClient.CheckKey()
+	}
+	if err != nil {
+		steamCheck.OK, steamCheck.Detail = false, err.Error()
+	}
+	checks = append(checks, steamCheck)
+
+	err = c.writeOutput(checks, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "SECRET\tSTATUS\tDETAIL")
+		for _, check := range checks {
+			status := "ok"
+			if !check.OK {
+				status = "failed"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Name, status, check.Detail)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		if !check.OK {
+			return fmt.Errorf("secrets check failed")
+		}
+	}
+	return nil
+}
+
+func presenceCheck(name string, value string) secretCheck {
+	if strings.TrimSpace(value) == "" {
+		return secretCheck{Name: name, Detail: "not set"}
+	}
+	return secretCheck{Name: name, OK: true}
+}
+
+// writeOutput writes v as indented JSON, or calls table to write it as a table
+func (c *clients) writeOutput(v interface{}, table func(tw *tabwriter.Writer)) error {
+	if c.options.output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"kanbanchan/internal/notion"
+	"kanbanchan/internal/steam"
+	"sort"
+	"strconv"
+)
+
+// minBackfillMatchScore is the lowest fuzzy match score accepted when linking
+// a page to a Steam app by title alone
+const minBackfillMatchScore = 0.95
+
+// planGames plans adding every library and wishlist game missing from the
+// Games DB and bringing the Steam-tracked columns of the rest up to date. It
+// reports whether some games were skipped because Steam couldn't return them
+func (c *clients) planGames() (*notion.Plan, bool, error) {
+	var partial *steam.PartialError
+	complete := true
+	library, err := c.steamClient.GetLibrary()
+	if errors.As(err, &partial) {
+		c.warnf("skipping library games that couldn't be retrieved: %s", partial.Error())
+		complete = false
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to get steam library: %s", err.Error())
+	}
+
+	wishlist, err := c.steamClient.GetWishlist()
+	if errors.As(err, &partial) {
+		c.warnf("skipping wishlist games that couldn't be retrieved: %s", partial.Error())
+		complete = false
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to get steam wishlist: %s", err.Error())
+	}
+
+	c.logf("retrieved %d library and %d wishlist game(s) from steam", len(*library), len(*wishlist))
+
+	notionGames, err := c.gameIndex()
+	if err != nil {
+		return nil, false, err
+	}
+
+	plan := &notion.Plan{}
+	seen := make(map[string]bool)
+	for _, games := range []*map[string]steam.SteamGame{library, wishlist} {
+		appIDs := make([]string, 0, len(*games))
+		for appID := range *games {
+			appIDs = append(appIDs, appID)
+		}
+		sort.Strings(appIDs)
+		for _, appID := range appIDs {
+			game := (*games)[appID]
+			if seen[appID] { // owned games take precedence over the wishlist
+				continue
+			}
+			seen[appID] = true
+
+			existing, ok := notionGames.Get(notion.PlatformSteam, appID)
+			if !ok {
+				op, err := c.notionClient.PlanAddGame(game)
+				if err != nil {
+					return nil, false, err
+				}
+				plan.Add(op)
+				continue
+			}
+
+			op, err := c.notionClient.PlanGameSync(existing, game)
+			if err != nil {
+				return nil, false, err
+			}
+			plan.Add(op)
+		}
+	}
+
+	// Archiving relies on knowing every wishlist game, so it's skipped when
+	// some couldn't be retrieved
+	if c.config.Notion.ArchiveRemovedWishlistGames && complete {
+		owned, err := c.steamClient.OwnedAppIDs()
+		if err != nil {
+			return nil, false, err
+		}
+		for _, game := range notionGames.RemovedWishlistGames(onSteam(owned, *wishlist)) {
+			plan.Add(c.notionClient.PlanArchive(game))
+		}
+	}
+
+	return plan, !complete, nil
+}
+
+// onSteam returns the app IDs still owned or wishlisted on Steam. The library
+// only holds games in a tracked collection, so ownership comes from every owned
+// game instead, otherwise a wishlisted game that was bought but not yet sorted
+// into a collection would look removed from the wishlist
+func onSteam(owned map[string]bool, wishlist map[string]steam.SteamGame) map[string]bool {
+	current := make(map[string]bool, len(owned)+len(wishlist))
+	for appID := range owned {
+		current[appID] = true
+	}
+	for appID := range wishlist {
+		current[appID] = true
+	}
+	return current
+}
+
+// gameIndex indexes every page in the Games DB by Steam app ID, warning about
+// pages that can't be matched to a Steam game
+func (c *clients) gameIndex() (*notion.GameIndex, error) {
+	pages, err := c.notionClient.ListGamePages(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notion games: %s", err.Error())
+	}
+
+	index := notion.NewGameIndex(pages)
+	for _, game := range index.Duplicates() {
+		c.warnf("skipping \"%s\": steam app id %s is already used by another page", game.Title(), game.SteamAppIDValue())
+	}
+	if unlinked := len(index.Unlinked()); unlinked > 0 {
+		c.warnf("%d game page(s) have no steam app id, run sync games -backfill-app-ids to link them", unlinked)
+	}
+	return index, nil
+}
+
+// planBackfillSteamAppIDs plans writing the Steam App ID of every game page
+// that doesn't have one yet, taking it from the Official Store Page URL or,
+// failing that, from a close match of the page title against the Steam app list
+func (c *clients) planBackfillSteamAppIDs() (*notion.Plan, error) {
+	pages, err := c.notionClient.ListGamePages(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notion games: %s", err.Error())
+	}
+
+	linked := make(map[string]string)
+	var unlinked []notion.GameProperties
+	for _, game := range pages {
+		if game.SteamAppID != nil && len(game.SteamAppID.RichText) != 0 {
+			linked[game.SteamAppIDValue()] = game.Title()
+			continue
+		}
+		unlinked = append(unlinked, game)
+	}
+
+	plan := &notion.Plan{}
+	for _, game := range unlinked {
+		title := game.Title()
+		appID := game.SteamAppIDValue()
+		if appID == "" {
+			matches, err := c.steamClient.SearchApps(title, 1)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search steam apps for \"%s\": %s", title, err.Error())
+			}
+			if len(matches) == 0 || matches[0].Score < minBackfillMatchScore {
+				c.warnf("no confident steam match for \"%s\", skipping", title)
+				continue
+			}
+			appID = strconv.Itoa(matches[0].AppID)
+		}
+
+		if other, ok := linked[appID]; ok {
+			c.warnf("skipping \"%s\": steam app id %s is already linked to \"%s\"", title, appID, other)
+			continue
+		}
+
+		op, err := c.notionClient.PlanLinkSteamApp(game, appID)
+		if err != nil {
+			return nil, err
+		}
+		plan.Add(op)
+		linked[appID] = title
+	}
+
+	return plan, nil
+}
+
+// planTransitions plans moving games between statuses by the configured
+// transition rules
+func (c *clients) planTransitions() (*notion.Plan, error) {
+	engine, err := notion.NewTransitionEngine(c.config.Transitions)
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := c.notionClient.ListGamePages(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notion games: %s", err.Error())
+	}
+
+	// Ownership and collections aren't on the page, so only ask Steam for
+	// them when a rule needs them
+	var owned map[string]bool
+	if engine.NeedsSteam() {
+		if c.steamClient == nil {
+			err = c.connectSteam()
+			if err != nil {
+				return nil, err
+			}
+		}
+		owned, err = c.steamClient.OwnedAppIDs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &notion.Plan{}
+	for _, game := range games {
+		input := notion.RuleInput{Game: game}
+		if appID := game.SteamAppIDValue(); owned != nil && appID != "" {
+			isOwned := owned[appID]
+			input.Owned = &isOwned
+			input.Collections = c.steamClient.AppCollections(appID)
+		}
+
+		rule := engine.Evaluate(input, c.clock)
+		if rule == nil {
+			continue
+		}
+		op, err := c.notionClient.PlanTransition(game, rule.To)
+		if err != nil {
+			return nil, err
+		}
+		if op != nil {
+			op.Reason = rule.Name
+		}
+		plan.Add(op)
+	}
+	return plan, nil
+}
+
+// planRecentlyPlayed plans writing Steam play activity to the Games DB,
+// promoting games played in the last two weeks to Playing and demoting
+// Playing games that haven't been touched in the configured number of days
+func (c *clients) planRecentlyPlayed() (*notion.Plan, error) {
+	rule := c.config.Steam.RecentlyPlayed
+	demoteTo := rule.DemoteTo
+	if demoteTo == "" {
+		demoteTo = notion.StatusUpNext
+	}
+
+	activity, err := c.steamClient.GetActivity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get steam activity: %s", err.Error())
+	}
+
+	notionGames, err := c.notionClient.ListGamePages(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notion games: %s", err.Error())
+	}
+
+	plan := &notion.Plan{}
+	for _, game := range notionGames {
+		gameActivity, ok := (*activity)[game.SteamAppIDValue()]
+		if !ok {
+			continue
+		}
+		status := notion.RecentlyPlayedStatus(game, gameActivity, rule.DemoteAfterDays, demoteTo, c.clock)
+		op, err := c.notionClient.PlanGameActivity(game, gameActivity, status)
+		if err != nil {
+			return nil, err
+		}
+		plan.Add(op)
+	}
+
+	return plan, nil
+}
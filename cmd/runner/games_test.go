@@ -0,0 +1,62 @@
+package main
+
+import (
+	"kanbanchan/internal/notion"
+	"kanbanchan/internal/steam"
+	"reflect"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// wishlistPage returns an Unowned page kanbanchan added for a Steam app
+func wishlistPage(title string, appID string) notion.GameProperties {
+	return notion.GameProperties{
+		Name:       &notionapi.TitleProperty{Title: []notionapi.RichText{{PlainText: title}}},
+		Status:     &notionapi.StatusProperty{Status: notionapi.Option{Name: notion.StatusUnowned}},
+		Platform:   &notionapi.MultiSelectProperty{MultiSelect: []notionapi.Option{{Name: "kanbanchan"}}},
+		SteamAppID: &notionapi.RichTextProperty{RichText: []notionapi.RichText{{PlainText: appID}}},
+	}
+}
+
+func TestOnSteamRemovedWishlistGames(t *testing.T) {
+	index := notion.NewGameIndex([]notion.GameProperties{
+		wishlistPage("Portal", "400"),
+		wishlistPage("Portal 2", "620"),
+		wishlistPage("Hades", "1145360"),
+	})
+
+	tests := []struct {
+		name     string
+		owned    map[string]bool
+		wishlist map[string]steam.SteamGame
+		want     []string
+	}{
+		{
+			name:     "still wishlisted",
+			wishlist: map[string]steam.SteamGame{"400": {}, "620": {}, "1145360": {}},
+		},
+		{
+			name:     "bought but not in a collection",
+			owned:    map[string]bool{"620": true},
+			wishlist: map[string]steam.SteamGame{"400": {}},
+			want:     []string{"Hades"},
+		},
+		{
+			name: "removed from the wishlist",
+			want: []string{"Hades", "Portal", "Portal 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, game := range index.RemovedWishlistGames(onSteam(tt.owned, tt.wishlist)) {
+				got = append(got, game.Title())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RemovedWishlistGames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,132 +2,309 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"kanbanchan/internal/anilist"
+	"kanbanchan/internal/aws"
+	"kanbanchan/internal/clock"
+	"kanbanchan/internal/config"
 	"kanbanchan/internal/notion"
 	"kanbanchan/internal/steam"
-	pkgnotion "kanbanchan/pkg/notion"
-	"time"
+	"kanbanchan/internal/tmdb"
+	pkgtmdb "kanbanchan/pkg/tmdb"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	_ "time/tzdata" // so the configured time zone loads on hosts without a zone database
+)
 
-	"github.com/jomei/notionapi"
+// Exit codes returned by the runner
+const (
+	exitOK      = 0
+	exitError   = 1
+	exitUsage   = 2
+	exitPartial = 3 // finished, but some games, movies or shows couldn't be retrieved
 )
 
+const usageText = `usage: runner [flags] <command> [command flags]
+
+commands:
+  sync games [-apply] [-backfill-app-ids]  plan adding and updating games from Steam
+  sync anime [-apply]                      plan adding and updating anime from AniList
+  sync movies [-apply]                     plan filling TMDB details into the Movies DB
+  sync tv [-apply]                         plan filling TMDB details into the TV DB and moving shows by progress
+  add movie [-year N] [-apply] <title|id>  plan adding a movie from TMDB
+  add show [-year N] [-apply] <title|id>   plan adding a show from TMDB
+  watch [-apply] <title|id> <SxxEyy>       plan recording the last episode watched of a show
+  transition [-apply]                      plan moving games between statuses by the transition rules
+  status                                   count the games on the board by status
+  lookup <name|appid>                      look up a game on Steam and the board
+  steam shortcuts                          list the non-Steam games added to the local Steam client
+  steam playtime                           list the playtime recorded by the local Steam client
+  db schema                                list the properties of the Games DB
+  db verify [kind]                         check a database has the properties kanbanchan expects
+  db provision [-apply] [kind]             add the properties and select options a database is missing
+  secrets check                            check the secrets needed to run
+  serve [-apply]                           run jobs on their schedules until stopped
+
+flags:
+`
+
 type clients struct {
-	steamClient  *steam.SteamClient
-	notionClient *notion.NotionClient
+	ctx           context.Context
+	steamClient   *steam.SteamClient
+	notionClient  *notion.NotionClient
+	aniListClient *anilist.AniListClient
+	tmdbClient    *tmdb.TMDBClient
+	config        *config.Config
+	clock         clock.Clock // tells the time in the configured time zone
+	options       globalOptions
+	partial       bool // some games, movies or shows were skipped; serve reports this per job instead
+}
+
+// globalOptions are the flags accepted before the command
+type globalOptions struct {
+	configPath  string
+	secretsPath string
+	env         string
+	output      string
+	verbose     bool
+}
+
+// usageError is returned for commands that were called incorrectly
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
 }
 
 func main() {
-	// testSuite() // quick output sanity check testing stuff
-	// =======================================================
+	os.Exit(run(os.Args[1:]))
+}
 
-	nc, err := notion.NewClient(context.Background())
-	if err != nil {
-		fmt.Printf("failed to create notion client: %s", err.Error())
-		return
+// run parses the command line, runs the command and returns the exit code
+func run(args []string) int {
+	var options globalOptions
+	flags := flag.NewFlagSet("runner", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usageText)
+		flags.PrintDefaults()
 	}
-
-	sc, err := steam.NewClient(context.Background())
-	if err != nil {
-		fmt.Printf("failed to create steam client: %s", err.Error())
-		return
+	flags.StringVar(&options.configPath, "config", config.DefaultPath, "path to the config file")
+	flags.StringVar(&options.secretsPath, "secrets", aws.SecretsPath, "path to the secrets file")
+	flags.StringVar(&options.env, "env", defaultEnvironment(), "environment, production uses the real databases and development, staging and local use the test ones")
+	flags.StringVar(&options.output, "output", "table", "output format, table or json")
+	flags.BoolVar(&options.verbose, "v", false, "log progress to stderr")
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	} else if err != nil {
+		return exitUsage
 	}
 
-	runner := clients{
-		steamClient:  sc,
-		notionClient: nc,
+	if options.output != "table" && options.output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %s, expected table or json\n", options.output)
+		return exitUsage
 	}
-
-	// err = runner.syncGames()
-	// if err != nil {
-	// 	fmt.Println(err.Error())
-	// 	return
-	// }
-
-	err = runner.transitionGames()
-	if err != nil {
-		fmt.Println(err.Error())
-		return
+	if !notion.ValidEnvironment(options.env) {
+		fmt.Fprintf(os.Stderr, "unknown environment %s\n", options.env)
+		return exitUsage
 	}
-}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	aws.SecretsPath = options.secretsPath
 
-func (c *clients) syncGames() error {
-	library, err := c.steamClient.GetLibrary()
+	cfg, err := config.Load(options.configPath)
 	if err != nil {
-		return fmt.Errorf("failed to get steam library: %s", err.Error())
+		fmt.Fprintf(os.Stderr, "failed to load config: %s\n", err.Error())
+		return exitError
 	}
-
-	wishlist, err := c.steamClient.GetWishlist()
+	clk, err := clock.Load(cfg.Timezone)
 	if err != nil {
-		return fmt.Errorf("failed to get steam wishlist: %s", err.Error())
+		fmt.Fprintf(os.Stderr, "failed to load time zone %s: %s\n", cfg.Timezone, err.Error())
+		return exitError
 	}
 
-	notionGames, err := c.notionClient.GetGamePages(nil)
-	if err != nil {
-		return fmt.Errorf("failed to get notion games: %s", err.Error())
+	// Interrupting or terminating the runner cancels the context every client
+	// is created with, so in-flight requests stop and serve shuts down cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := &clients{ctx: ctx, config: cfg, clock: clk, options: options}
+	err = c.runCommand(flags.Args())
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(os.Stderr, err.Error())
+		flags.Usage()
+		return exitUsage
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitError
+	}
+	if c.partial {
+		return exitPartial
 	}
+	return exitOK
+}
 
-	// For every game in library, check to see if its in notionGames. If not, add
-	for _, game := range *library {
-		_, ok := (*notionGames)[game.Name]
-		if !ok {
-			err := c.notionClient.AddGame(game)
-			if err != nil {
-				return fmt.Errorf("failed to add game %s: %s", game.Name, err.Error())
-			}
+// runCommand runs the command named by the first argument
+func (c *clients) runCommand(args []string) error {
+	command, args := args[0], args[1:]
+	switch command {
+	case "sync":
+		if len(args) > 0 && args[0] == "games" {
+			return c.syncGamesCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "anime" {
+			return c.syncAnimeCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "movies" {
+			return c.syncMoviesCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "tv" {
+			return c.syncTVCommand(args[1:])
+		}
+		return usageError{"sync needs a subcommand: games, anime, movies or tv"}
+	case "add":
+		if len(args) > 0 && args[0] == "movie" {
+			return c.addMovieCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "show" {
+			return c.addShowCommand(args[1:])
+		}
+		return usageError{"add needs a subcommand: movie or show"}
+	case "watch":
+		return c.watchCommand(args)
+	case "transition":
+		return c.transitionCommand(args)
+	case "status":
+		return c.statusCommand(args)
+	case "lookup":
+		return c.lookupCommand(args)
+	case "steam":
+		if len(args) > 0 && args[0] == "shortcuts" {
+			return c.steamShortcutsCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "playtime" {
+			return c.steamPlaytimeCommand(args[1:])
+		}
+		return usageError{"steam needs a subcommand: shortcuts or playtime"}
+	case "db":
+		if len(args) > 0 && args[0] == "schema" {
+			return c.dbSchemaCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "verify" {
+			return c.dbVerifyCommand(args[1:])
+		} else if len(args) > 0 && args[0] == "provision" {
+			return c.dbProvisionCommand(args[1:])
+		}
+		return usageError{"db needs a subcommand: schema, verify or provision"}
+	case "secrets":
+		if len(args) == 0 || args[0] != "check" {
+			return usageError{"secrets needs a subcommand: check"}
 		}
+		return c.secretsCheckCommand(args[1:])
+	case "serve":
+		return c.serveCommand(args)
 	}
+	return usageError{fmt.Sprintf("unknown command %s", command)}
+}
 
-	// For every game in wishlist, check to see if its in notionGames. If not, add
-	for _, game := range *wishlist {
-		_, ok := (*notionGames)[game.Name]
+// connectNotion creates the Notion client for the selected environment
+func (c *clients) connectNotion() error {
+	opts := []notion.ClientOption{
+		notion.WithEnvironment(c.options.env),
+		notion.WithClock(c.clock),
+		notion.WithAutoFinish(c.config.Steam.AutoFinishCompleted),
+		notion.WithUserOwnedProperties(c.config.Notion.UserOwnedProperties...),
+	}
+	for database, names := range c.config.Notion.Properties {
+		kind, ok := notion.LookupMediaKind(database)
 		if !ok {
-			err := c.notionClient.AddGame(game)
-			if err != nil {
-				return fmt.Errorf("failed to add game %s: %s", game.Name, err.Error())
+			return fmt.Errorf("unknown database %s in notion.properties, expected games, anime, movies or tv", database)
+		}
+		for field := range names {
+			if _, ok := kind.Property(field); !ok {
+				return fmt.Errorf("unknown %s field %s in notion.properties", kind.Name, field)
 			}
 		}
+		opts = append(opts, notion.WithPropertyNames(kind, names))
+	}
+	nc, err := notion.NewClient(c.ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create notion client: %s", err.Error())
 	}
+	c.notionClient = nc
+	return nil
+}
 
+// connectSteam creates the Steam client
+func (c *clients) connectSteam() error {
+	sc, err := steam.NewClient(c.ctx,
+		steam.WithClock(c.clock),
+		steam.WithInstallPath(c.config.Steam.InstallPath),
+		steam.WithCollectionNames(c.config.Steam.Collections),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create steam client: %s", err.Error())
+	}
+	c.steamClient = sc
 	return nil
 }
 
-func (c *clients) transitionGames() error {
-	options := &notionapi.DatabaseQueryRequest{
-		Filter: notionapi.PropertyFilter{
-			Property: "Status",
-			Status: &notionapi.StatusFilterCondition{
-				Equals: notion.StatusUnreleased,
-			},
-		},
+// connectAniList creates the AniList client
+func (c *clients) connectAniList() error {
+	ac, err := anilist.NewClient(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create anilist client: %s", err.Error())
 	}
+	c.aniListClient = ac
+	return nil
+}
 
-	games, err := c.notionClient.GetGamePages(options)
+// connectTMDB creates the TMDB client for the configured region and language
+func (c *clients) connectTMDB() error {
+	tc, err := tmdb.NewClient(c.ctx,
+		tmdb.WithRegion(c.config.TMDB.Region),
+		tmdb.WithAPIOptions(pkgtmdb.WithLanguage(c.config.TMDB.Language)),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to get unreleased games: %s", err.Error())
+		return fmt.Errorf("failed to create tmdb client: %s", err.Error())
 	}
+	c.tmdbClient = tc
+	return nil
+}
 
-	nc := pkgnotion.NotionClient{}
-	for title, game := range *games {
-		if game.ReleaseDate == nil || game.ReleaseDate.Date == nil || game.ReleaseDate.Date.Start == nil {
-			continue
-		}
+// parseCommandFlags parses flags given after a command, rejecting leftover arguments
+func parseCommandFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(os.Stderr)
+	err := flags.Parse(args)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	if flags.NArg() > 0 {
+		return usageError{fmt.Sprintf("unexpected arguments: %s", strings.Join(flags.Args(), " "))}
+	}
+	return nil
+}
 
-		releaseDate, err := nc.ParseNotionDate(*game.ReleaseDate.Date.Start)
-		if err != nil {
-			return fmt.Errorf("failed to parse release date for \"%s\": %s", title, err.Error())
-		}
-		if time.Now().Before(releaseDate) {
-			gamePage, err := c.notionClient.GetGamePageByID(game.PageID)
-			if err != nil {
-				return fmt.Errorf("failed to retrieve game \"%s\" by id %s: %s", game.Name.Title[0].PlainText, game.PageID, err.Error())
-			}
-			gamePage.Properties["Status"].(*notionapi.StatusProperty).Status.Name = notion.StatusUnowned
+// logf prints progress to stderr when running verbosely
+func (c *clients) logf(format string, args ...interface{}) {
+	if c.options.verbose {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
 
-			err = c.notionClient.UpdateGame(game.PageID, gamePage.Properties)
-			if err != nil {
-				return fmt.Errorf("failed to transition %s to status Unowned: %s", game.Name.Title[0].PlainText, err.Error())
-			}
-		}
+// warnf prints a warning to stderr, keeping stdout for command output
+func (c *clients) warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// defaultEnvironment falls back to the ENVIRONMENT variable so existing
+// setups keep using the same databases
+func defaultEnvironment() string {
+	if env := os.Getenv("ENVIRONMENT"); env != "" {
+		return env
 	}
-	return nil
+	return notion.EnvProduction
 }
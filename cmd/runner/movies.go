@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"kanbanchan/internal/notion"
+	"kanbanchan/internal/tmdb"
+	"os"
+	"strings"
+)
+
+// syncMoviesCommand plans filling TMDB details into every page in the Movies
+// DB, applying the plan when -apply is given
+func (c *clients) syncMoviesCommand(args []string) error {
+	flags := flag.NewFlagSet("sync movies", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectTMDB()
+	if err != nil {
+		return err
+	}
+	plan, partial, err := c.planMovies()
+	if err != nil {
+		return err
+	}
+	c.partial = partial
+	return c.finishPlan(plan, *apply)
+}
+
+// addMovieCommand plans adding a movie to the Movies DB by title or TMDB ID,
+// applying the plan when -apply is given
+func (c *clients) addMovieCommand(args []string) error {
+	flags := flag.NewFlagSet("add movie", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	year := flags.Int("year", 0, "only match movies released in this year")
+	err := flags.Parse(args)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	query := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if query == "" {
+		return usageError{"add movie needs a title or TMDB ID"}
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectTMDB()
+	if err != nil {
+		return err
+	}
+
+	var movie *tmdb.Movie
+	if appIDPattern.MatchString(query) {
+		movie, err = c.tmdbClient.GetMovie(query)
+	} else {
+		movie, err = c.tmdbClient.FindMovie(query, *year)
+	}
+	if err != nil {
+		return err
+	}
+	if movie == nil {
+		return fmt.Errorf("no movie on tmdb matches \"%s\", try -year or its TMDB ID", query)
+	}
+
+	pages, err := c.notionClient.ListMoviePages(nil)
+	if err != nil {
+		return err
+	}
+	var op *notion.Operation
+	if existing, ok := notion.FindMoviePage(pages, *movie); ok {
+		c.warnf("%s is already in the movies database, updating it instead", existing.Title())
+		op, err = c.notionClient.PlanMovieSync(existing, *movie)
+	} else {
+		op, err = c.notionClient.PlanAddMovie(*movie)
+	}
+	if err != nil {
+		return err
+	}
+	plan := &notion.Plan{}
+	plan.Add(op)
+	return c.finishPlan(plan, *apply)
+}
+
+// planMovies plans bringing the TMDB details of every movie page up to date.
+// Pages without a TMDB ID are matched by title and release year, and are
+// skipped with a warning when there's no confident match. Pages TMDB can't
+// return are skipped too, and reported by returning true
+func (c *clients) planMovies() (*notion.Plan, bool, error) {
+	pages, err := c.notionClient.ListMoviePages(nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get notion movies: %s", err.Error())
+	}
+
+	plan := &notion.Plan{}
+	partial := false
+	for _, page := range pages {
+		var movie *tmdb.Movie
+		if id := page.TMDBIDValue(); id != "" {
+			movie, err = c.tmdbClient.GetMovie(id)
+		} else {
+			movie, err = c.tmdbClient.FindMovie(page.Title(), page.ReleaseYear())
+		}
+		if c.ctx.Err() != nil {
+			return nil, false, c.ctx.Err()
+		}
+		if err != nil {
+			c.warnf("skipping \"%s\": %s", page.Title(), err.Error())
+			partial = true
+			continue
+		}
+		if movie == nil {
+			c.warnf("skipping \"%s\": no confident match on tmdb, add its TMDB ID to link it", page.Title())
+			continue
+		}
+
+		op, err := c.notionClient.PlanMovieSync(page, *movie)
+		if err != nil {
+			return nil, false, err
+		}
+		plan.Add(op)
+	}
+	return plan, partial, nil
+}
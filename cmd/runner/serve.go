@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"kanbanchan/internal/config"
+	"kanbanchan/internal/notion"
+	"kanbanchan/internal/scheduler"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	defaultServeAddress = ":8080"
+	shutdownTimeout     = 10 * time.Second
+)
+
+// defaultJobSchedules are used when the config doesn't schedule any jobs
+var defaultJobSchedules = map[string]string{
+	"sync-games":       "0 */6 * * *",
+	"transition":       "15 0 * * *",
+	"refresh-app-list": "@daily",
+}
+
+// serveCommand runs jobs on their schedules until the process is interrupted
+// or terminated, serving a health endpoint alongside them
+func (c *clients) serveCommand(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only logging them")
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := c.scheduledJobs(*apply)
+	if err != nil {
+		return err
+	}
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectSteam()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		switch job.Name {
+		case "sync-anime":
+			err = c.connectAniList()
+		case "sync-movies", "sync-tv":
+			err = c.connectTMDB()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	statePath := c.config.Serve.StatePath
+	if statePath == "" {
+		statePath = config.DefaultStatePath
+	}
+	sched, err := scheduler.New(statePath, jobs...)
+	if err != nil {
+		return err
+	}
+
+	address := c.config.Serve.Address
+	if address == "" {
+		address = defaultServeAddress
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", sched.HealthHandler())
+	server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	serverErr := make(chan error, 1)
+	go func() {
+		err := server.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	go func() {
+		// Stop the jobs when the health endpoint can't be served
+		err, ok := <-serverErr
+		if ok {
+			log.Printf("health endpoint failed: %s", err.Error())
+			cancel()
+		}
+	}()
+
+	for _, job := range jobs {
+		log.Printf("scheduled %s for %s", job.Name, job.Schedule)
+	}
+	log.Printf("serving health on %s, apply is %t", address, *apply)
+	err = sched.Run(ctx)
+	log.Printf("shutting down")
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	shutdownErr := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+	if shutdownErr != nil {
+		return fmt.Errorf("failed to shut down health endpoint: %s", shutdownErr.Error())
+	}
+	return nil
+}
+
+// scheduledJobs returns the jobs to run with their schedules from the config
+func (c *clients) scheduledJobs(apply bool) ([]scheduler.Job, error) {
+	runs := map[string]func(ctx context.Context) error{
+		"sync-games": func(ctx context.Context) error {
+			plan, partial, err := c.planGames()
+			if err != nil {
+				return err
+			}
+			if c.config.Steam.RecentlyPlayed.Enabled {
+				activityPlan, err := c.planRecentlyPlayed()
+				if err != nil {
+					return err
+				}
+				plan.Merge(activityPlan)
+			}
+			return c.runPlan(ctx, plan, partial, apply)
+		},
+		"transition": func(ctx context.Context) error {
+			plan, err := c.planTransitions()
+			if err != nil {
+				return err
+			}
+			return c.runPlan(ctx, plan, false, apply)
+		},
+		"refresh-app-list": func(ctx context.Context) error {
+			return c.steamClient.RefreshAppList()
+		},
+		"sync-anime": func(ctx context.Context) error {
+			plan, err := c.planAnime()
+			if err != nil {
+				return err
+			}
+			return c.runPlan(ctx, plan, false, apply)
+		},
+		"sync-movies": func(ctx context.Context) error {
+			plan, partial, err := c.planMovies()
+			if err != nil {
+				return err
+			}
+			return c.runPlan(ctx, plan, partial, apply)
+		},
+		"sync-tv": func(ctx context.Context) error {
+			plan, partial, err := c.planTV()
+			if err != nil {
+				return err
+			}
+			return c.runPlan(ctx, plan, partial, apply)
+		},
+	}
+
+	schedules := c.config.Serve.Jobs
+	if len(schedules) == 0 {
+		schedules = defaultJobSchedules
+	}
+	names := make([]string, 0, len(schedules))
+	for name := range schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var jobs []scheduler.Job
+	for _, name := range names {
+		run, ok := runs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown job %s", name)
+		}
+		if schedules[name] == "" {
+			continue
+		}
+		schedule, err := scheduler.ParseCron(schedules[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to schedule %s: %s", name, err.Error())
+		}
+		jobs = append(jobs, scheduler.Job{Name: name, Schedule: schedule.In(c.clock.Location()), Run: run})
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("no jobs are scheduled")
+	}
+	return jobs, nil
+}
+
+// runPlan logs a plan built by a scheduled job and applies it when asked to.
+// A plan that had to skip some items is reported to the scheduler as partial
+// once it's applied
+func (c *clients) runPlan(ctx context.Context, plan *notion.Plan, partial bool, apply bool) error {
+	log.Print(plan.Summary())
+	if apply && len(plan.Operations) > 0 {
+		if ctx.Err() != nil { // shutting down, leave the changes for the next run
+			return ctx.Err()
+		}
+		err := c.notionClient.ApplyPlan(plan)
+		if err != nil {
+			return err
+		}
+	}
+	if partial {
+		return scheduler.ErrPartial
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"kanbanchan/internal/notion"
+	"kanbanchan/internal/tmdb"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// episodePattern matches an episode code like "S02E05" or "s2e5"
+var episodePattern = regexp.MustCompile(`(?i)^s(\d+)e(\d+)$`)
+
+// syncTVCommand plans filling TMDB details into every page in the TV DB and
+// moving shows between statuses as episodes air, applying the plan when
+// -apply is given
+func (c *clients) syncTVCommand(args []string) error {
+	flags := flag.NewFlagSet("sync tv", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	err := parseCommandFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectTMDB()
+	if err != nil {
+		return err
+	}
+	plan, partial, err := c.planTV()
+	if err != nil {
+		return err
+	}
+	c.partial = partial
+	return c.finishPlan(plan, *apply)
+}
+
+// addShowCommand plans adding a show to the TV DB by title or TMDB ID,
+// applying the plan when -apply is given
+func (c *clients) addShowCommand(args []string) error {
+	flags := flag.NewFlagSet("add show", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	year := flags.Int("year", 0, "only match shows that first aired in this year")
+	err := flags.Parse(args)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	query := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if query == "" {
+		return usageError{"add show needs a title or TMDB ID"}
+	}
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectTMDB()
+	if err != nil {
+		return err
+	}
+
+	var show *tmdb.Show
+	if appIDPattern.MatchString(query) {
+		show, err = c.tmdbClient.GetShow(query)
+	} else {
+		show, err = c.tmdbClient.FindShow(query, *year)
+	}
+	if err != nil {
+		return err
+	}
+	if show == nil {
+		return fmt.Errorf("no show on tmdb matches \"%s\", try -year or its TMDB ID", query)
+	}
+
+	pages, err := c.notionClient.ListTVPages(nil)
+	if err != nil {
+		return err
+	}
+	var op *notion.Operation
+	if existing, ok := notion.FindTVPage(pages, *show); ok {
+		c.warnf("%s is already in the tv database, updating it instead", existing.Title())
+		op, err = c.notionClient.PlanShowSync(existing, *show)
+	} else {
+		op, err = c.notionClient.PlanAddShow(*show)
+	}
+	if err != nil {
+		return err
+	}
+	plan := &notion.Plan{}
+	plan.Add(op)
+	return c.finishPlan(plan, *apply)
+}
+
+// watchCommand plans recording the last episode watched of a show in the TV
+// DB, applying the plan when -apply is given
+func (c *clients) watchCommand(args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	apply := flags.Bool("apply", false, "apply the planned changes to Notion instead of only printing them")
+	err := flags.Parse(args)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	if flags.NArg() < 2 {
+		return usageError{"watch needs a show title or TMDB ID and an episode like S01E05"}
+	}
+	query := strings.TrimSpace(strings.Join(flags.Args()[:flags.NArg()-1], " "))
+	match := episodePattern.FindStringSubmatch(flags.Arg(flags.NArg() - 1))
+	if match == nil {
+		return usageError{fmt.Sprintf("invalid episode %s, expected one like S01E05", flags.Arg(flags.NArg()-1))}
+	}
+	season, _ := strconv.Atoi(match[1])
+	episode, _ := strconv.Atoi(match[2])
+
+	err = c.connectNotion()
+	if err != nil {
+		return err
+	}
+	err = c.connectTMDB()
+	if err != nil {
+		return err
+	}
+
+	pages, err := c.notionClient.ListTVPages(nil)
+	if err != nil {
+		return err
+	}
+	var existing *notion.TVProperties
+	for i, page := range pages {
+		if page.TMDBIDValue() == query || strings.EqualFold(strings.TrimSpace(page.Title()), query) {
+			existing = &pages[i]
+			break
+		}
+	}
+	if existing == nil {
+		return fmt.Errorf("no show in the tv database matches \"%s\", add it with add show first", query)
+	}
+
+	var show *tmdb.Show
+	if id := existing.TMDBIDValue(); id != "" {
+		show, err = c.tmdbClient.GetShow(id)
+	} else {
+		show, err = c.tmdbClient.FindShow(existing.Title(), existing.FirstAirYear())
+	}
+	if err != nil {
+		return err
+	}
+	if show == nil {
+		return fmt.Errorf("no confident match on tmdb for \"%s\", add its TMDB ID to link it", existing.Title())
+	}
+
+	op, err := c.notionClient.PlanShowProgress(*existing, *show, season, episode)
+	if err != nil {
+		return err
+	}
+	plan := &notion.Plan{}
+	plan.Add(op)
+	return c.finishPlan(plan, *apply)
+}
+
+// planTV plans bringing the TMDB details and status of every show page up to
+// date. Pages without a TMDB ID are matched by title and first air year, and
+// are skipped with a warning when there's no confident match. Pages TMDB
+// can't return are skipped too, and reported by returning true
+func (c *clients) planTV() (*notion.Plan, bool, error) {
+	pages, err := c.notionClient.ListTVPages(nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get notion shows: %s", err.Error())
+	}
+
+	plan := &notion.Plan{}
+	partial := false
+	for _, page := range pages {
+		var show *tmdb.Show
+		if id := page.TMDBIDValue(); id != "" {
+			show, err = c.tmdbClient.GetShow(id)
+		} else {
+			show, err = c.tmdbClient.FindShow(page.Title(), page.FirstAirYear())
+		}
+		if c.ctx.Err() != nil {
+			return nil, false, c.ctx.Err()
+		}
+		if err != nil {
+			c.warnf("skipping \"%s\": %s", page.Title(), err.Error())
+			partial = true
+			continue
+		}
+		if show == nil {
+			c.warnf("skipping \"%s\": no confident match on tmdb, add its TMDB ID to link it", page.Title())
+			continue
+		}
+
+		op, err := c.notionClient.PlanShowSync(page, *show)
+		if err != nil {
+			return nil, false, err
+		}
+		plan.Add(op)
+	}
+	return plan, partial, nil
+}
@@ -0,0 +1,131 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"kanbanchan/internal/aws"
+	"kanbanchan/pkg/anilist"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statuses of anime on the authenticated user's list that are synced
+const (
+	StatusWatching = anilist.StatusCurrent
+	StatusFinished = anilist.StatusCompleted
+	StatusPlanning = anilist.StatusPlanning
+)
+
+// AniListClient contains a client and the user whose lists are read
+type AniListClient struct {
+	aniList  *anilist.AniListClient
+	userName string
+	settings struct {
+		apiOptions []anilist.ClientOption
+	}
+}
+
+// ClientOption configures optional settings on an AniListClient
+type ClientOption func(*AniListClient)
+
+// Anime contains info about an anime on the user's list
+type Anime struct {
+	ID          string                  `json:"id"`
+	Title       string                  `json:"title"`
+	Status      anilist.MediaListStatus `json:"status"`
+	Progress    int                     `json:"progress"`
+	Episodes    int                     `json:"episodes,omitempty"` // 0 while unknown
+	Score       float64                 `json:"score,omitempty"`    // out of 10
+	Format      string                  `json:"format,omitempty"`
+	Season      string                  `json:"season,omitempty"` // like "Spring 2024"
+	Genres      []string                `json:"genres,omitempty"`
+	CoverImage  string                  `json:"coverImage,omitempty"`
+	SiteURL     string                  `json:"siteURL"`
+	StartedAt   time.Time               `json:"startedAt"`   // zero when unknown
+	CompletedAt time.Time               `json:"completedAt"` // zero when unknown
+}
+
+// NewClient creates a client for the AniList user in secrets, authenticated
+// when secrets has a token
+func NewClient(ctx context.Context, opts ...ClientOption) (*AniListClient, error) {
+	var client AniListClient
+	var secrets, err = aws.GetSecrets()
+	if err != nil {
+		return nil, err
+	}
+	client.userName = strings.TrimSpace(secrets.AniList.UserName)
+	if client.userName == "" {
+		return nil, fmt.Errorf("no anilist user name in secrets")
+	}
+	for _, opt := range opts {
+		opt(&client)
+	}
+
+	apiOptions := append([]anilist.ClientOption{
+		anilist.WithToken(secrets.AniList.Token),
+	}, client.settings.apiOptions...)
+	aniListClient, err := anilist.NewClient(ctx, apiOptions...)
+	if err != nil {
+		return nil, err
+	}
+	client.aniList = aniListClient
+	return &client, nil
+}
+
+// WithAPIOptions passes options through to the underlying AniList API client
+func WithAPIOptions(opts ...anilist.ClientOption) ClientOption {
+	return func(ac *AniListClient) {
+		ac.settings.apiOptions = append(ac.settings.apiOptions, opts...)
+	}
+}
+
+// GetAnimeList gets the anime the user is watching, has finished or is
+// planning to watch, keyed by AniList ID
+func (ac *AniListClient) GetAnimeList() (map[string]Anime, error) {
+	entries, err := ac.aniList.GetUserAnimeList(ac.userName, StatusWatching, StatusFinished, StatusPlanning)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(map[string]Anime)
+	for _, entry := range entries {
+		anime := newAnime(entry)
+		list[anime.ID] = anime
+	}
+	return list, nil
+}
+
+// newAnime populates an Anime from a list entry
+func newAnime(entry anilist.MediaListEntry) Anime {
+	media := entry.Media
+	anime := Anime{
+		ID:          strconv.Itoa(media.ID),
+		Title:       mediaTitle(media.Title),
+		Status:      entry.Status,
+		Progress:    entry.Progress,
+		Episodes:    media.Episodes,
+		Score:       entry.Score,
+		Format:      media.Format,
+		Genres:      media.Genres,
+		CoverImage:  media.CoverImage.Large,
+		SiteURL:     media.SiteURL,
+		StartedAt:   entry.StartedAt.Time(),
+		CompletedAt: entry.CompletedAt.Time(),
+	}
+	if media.Season != "" && media.SeasonYear > 0 {
+		season := strings.ToUpper(media.Season[:1]) + strings.ToLower(media.Season[1:])
+		anime.Season = fmt.Sprintf("%s %d", season, media.SeasonYear)
+	}
+	return anime
+}
+
+// mediaTitle picks the English title, falling back to romaji and then native
+func mediaTitle(title anilist.MediaTitle) string {
+	for _, name := range []string{title.English, title.UserPreferred, title.Romaji, title.Native} {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 )
 
+// SecretsPath is where GetSecrets reads local secrets from
+var SecretsPath = "../../local/secrets.json"
+
 type SecretsClient struct {
 }
 
@@ -15,6 +18,10 @@ func NewClient(ctx context.Context) (*SecretsClient, error) {
 
 // LocalKeys mimics the JSON structure of local key storage
 type LocalSecrets struct {
+	AniList struct {
+		UserName string `json:"userName"`
+		Token    string `json:"token"`
+	} `json:"aniList"`
 	Discord struct {
 		Key string `json:"key"`
 	} `json:"discord"`
@@ -42,12 +49,15 @@ type LocalSecrets struct {
 			UpNext   []json.Number `json:"upNext"`
 		} `json:"collections"`
 	} `json:"steam"`
+	TMDB struct {
+		Key string `json:"key"`
+	} `json:"tmdb"`
 }
 
 // GetSecrets retrieves secrets
 func GetSecrets() (*LocalSecrets, error) {
 	var keys LocalSecrets
-	fileContent, err := os.ReadFile("../../local/secrets.json")
+	fileContent, err := os.ReadFile(SecretsPath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,94 @@
+// Package clock tells the current time in the user's time zone, so status
+// logic can be run against a fixed time and compares dates by calendar day.
+package clock
+
+import "time"
+
+// Clock tells the current time in a time zone
+type Clock interface {
+	Now() time.Time
+	Location() *time.Location
+}
+
+type systemClock struct {
+	location *time.Location
+}
+
+// New returns a clock that reads the system time in location, or in the
+// local time zone when location is nil
+func New(location *time.Location) Clock {
+	if location == nil {
+		location = time.Local
+	}
+	return systemClock{location: location}
+}
+
+func (c systemClock) Now() time.Time {
+	return time.Now().In(c.location)
+}
+
+func (c systemClock) Location() *time.Location {
+	return c.location
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+// Fixed returns a clock that always reads now, in now's time zone
+func Fixed(now time.Time) Clock {
+	return fixedClock{now: now}
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) Location() *time.Location {
+	return c.now.Location()
+}
+
+// Load returns a clock for the named IANA time zone, such as
+// "America/New_York", or the local time zone when name is empty
+func Load(name string) (Clock, error) {
+	if name == "" {
+		return New(nil), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	return New(location), nil
+}
+
+// Day returns the calendar day t falls on as midnight UTC, so days compare
+// the same whatever zone they were written in. Times at midnight in their own
+// zone, like date-only values and dates written with Midnight, already name a
+// day. Other times fall on their day in the clock's zone. An instant that
+// happens to be midnight in a zone other than the clock's is read the same
+// way, so instants should be passed in the clock's zone
+func Day(t time.Time, c Clock) time.Time {
+	if hour, min, sec := t.Clock(); hour != 0 || min != 0 || sec != 0 || t.Nanosecond() != 0 {
+		t = t.In(c.Location())
+	}
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Today returns the clock's current calendar day as midnight UTC
+func Today(c Clock) time.Time {
+	return Day(c.Now().In(c.Location()), c)
+}
+
+// OnOrBeforeToday reports whether the day date falls on has arrived in the
+// clock's zone
+func OnOrBeforeToday(date time.Time, c Clock) bool {
+	return !Day(date, c).After(Today(c))
+}
+
+// Midnight returns the start of the day date falls on in the clock's zone,
+// for writing dates that should read as the same day to the user
+func Midnight(date time.Time, c Clock) time.Time {
+	year, month, day := Day(date, c).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, c.Location())
+}
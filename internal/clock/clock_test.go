@@ -0,0 +1,137 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone data isn't available: %s", err.Error())
+	}
+	return location
+}
+
+func dateOnly(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDay(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	c := Fixed(time.Date(2026, 10, 16, 9, 0, 0, 0, newYork))
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "date-only", t: dateOnly(2026, 10, 16), want: dateOnly(2026, 10, 16)},
+		{name: "midnight in the clock's zone", t: time.Date(2026, 10, 16, 0, 0, 0, 0, newYork), want: dateOnly(2026, 10, 16)},
+		{name: "late in the clock's zone", t: time.Date(2026, 10, 16, 23, 30, 0, 0, newYork), want: dateOnly(2026, 10, 16)},
+		{name: "utc instant on the previous day in the clock's zone", t: time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), want: dateOnly(2026, 10, 15)},
+		{name: "tokyo instant on the previous day in the clock's zone", t: time.Date(2026, 10, 16, 9, 0, 0, 0, tokyo), want: dateOnly(2026, 10, 15)},
+		// Midnight in its own zone names a day even when it's an instant on
+		// another day in the clock's zone
+		{name: "midnight in another zone", t: time.Date(2026, 10, 16, 0, 0, 0, 0, tokyo), want: dateOnly(2026, 10, 16)},
+		{name: "a nanosecond past midnight in another zone", t: time.Date(2026, 10, 16, 0, 0, 0, 1, tokyo), want: dateOnly(2026, 10, 15)},
+		// New York falls back from UTC-4 to UTC-5 at 2am on November 1st
+		{name: "before the dst change", t: time.Date(2026, 11, 1, 3, 30, 0, 0, time.UTC), want: dateOnly(2026, 10, 31)},
+		{name: "after the dst change", t: time.Date(2026, 11, 2, 4, 30, 0, 0, time.UTC), want: dateOnly(2026, 11, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Day(tt.t, c); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("Day(%s) = %s, want %s", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "utc", now: time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC), want: dateOnly(2026, 10, 16)},
+		{name: "evening in new york", now: time.Date(2026, 10, 16, 23, 30, 0, 0, newYork), want: dateOnly(2026, 10, 16)},
+		{name: "midnight in tokyo", now: time.Date(2026, 10, 17, 0, 0, 0, 0, tokyo), want: dateOnly(2026, 10, 17)},
+		{name: "repeated hour of the dst change", now: time.Date(2026, 11, 1, 1, 30, 0, 0, newYork).Add(time.Hour), want: dateOnly(2026, 11, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Today(Fixed(tt.now)); !got.Equal(tt.want) {
+				t.Errorf("Today() at %s = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnOrBeforeToday(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	c := Fixed(time.Date(2026, 10, 16, 9, 0, 0, 0, newYork))
+
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{name: "yesterday", date: dateOnly(2026, 10, 15), want: true},
+		{name: "today", date: dateOnly(2026, 10, 16), want: true},
+		{name: "tomorrow", date: dateOnly(2026, 10, 17), want: false},
+		{name: "instant later today in the clock's zone", date: time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC), want: true},
+		{name: "instant tomorrow in the clock's zone", date: time.Date(2026, 10, 17, 5, 0, 0, 0, time.UTC), want: false},
+		// Still the 16th in New York, but midnight in Tokyo names the 17th
+		{name: "midnight tomorrow in another zone", date: time.Date(2026, 10, 17, 0, 0, 0, 0, tokyo), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OnOrBeforeToday(tt.date, c); got != tt.want {
+				t.Errorf("OnOrBeforeToday(%s) = %t, want %t", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMidnight(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	c := Fixed(time.Date(2026, 10, 16, 9, 0, 0, 0, newYork))
+
+	tests := []struct {
+		name string
+		date time.Time
+		want time.Time
+	}{
+		{name: "date-only", date: dateOnly(2026, 10, 16), want: time.Date(2026, 10, 16, 0, 0, 0, 0, newYork)},
+		{name: "instant in the clock's zone", date: time.Date(2026, 10, 16, 18, 0, 0, 0, newYork), want: time.Date(2026, 10, 16, 0, 0, 0, 0, newYork)},
+		{name: "utc instant on the previous day in the clock's zone", date: time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), want: time.Date(2026, 10, 16, 0, 0, 0, 0, newYork)},
+		{name: "midnight in another zone", date: time.Date(2026, 10, 16, 0, 0, 0, 0, tokyo), want: time.Date(2026, 10, 16, 0, 0, 0, 0, newYork)},
+		// The days either side of the change start at different offsets
+		{name: "day of the dst change", date: dateOnly(2026, 11, 1), want: time.Date(2026, 11, 1, 4, 0, 0, 0, time.UTC)},
+		{name: "day after the dst change", date: dateOnly(2026, 11, 2), want: time.Date(2026, 11, 2, 5, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Midnight(tt.date, c)
+			if !got.Equal(tt.want) || got.Location() != newYork {
+				t.Errorf("Midnight(%s) = %s, want %s in %s", tt.date, got, tt.want, newYork)
+			}
+			// A day written with Midnight reads back as the same day
+			if day := Day(got, c); !day.Equal(Day(tt.date, c)) {
+				t.Errorf("Day(Midnight(%s)) = %s, want %s", tt.date, day, Day(tt.date, c))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kanbanchan/internal/notion"
+	"os"
+	"strings"
+)
+
+// DefaultPath is where the runner looks for its config, alongside local secrets
+const DefaultPath = "../../local/config.json"
+
+// DefaultStatePath is where serve saves job state when the config doesn't say
+const DefaultStatePath = "../../local/state.json"
+
+// Config contains settings for the runner that aren't secrets. Every setting
+// is optional and a missing config file behaves like an empty one
+type Config struct {
+	// Timezone is the IANA time zone, like "America/New_York", that dates
+	// are written in, release days are compared in and jobs are scheduled in.
+	// The local time zone is used when empty
+	Timezone string `json:"timezone"`
+	Steam    struct {
+		// InstallPath is where the Steam client is installed. When empty the
+		// default location for the OS is used if it exists
+		InstallPath string `json:"installPath"`
+		// Collections maps Steam library collection names to the Finished,
+		// Playing and Up Next collections kanbanchan tracks
+		Collections map[string]string `json:"collections"`
+		// AutoFinishCompleted moves games with every achievement unlocked to Finished
+		AutoFinishCompleted bool `json:"autoFinishCompleted"`
+		// RecentlyPlayed moves games between Playing and another status based on Steam activity
+		RecentlyPlayed struct {
+			Enabled bool `json:"enabled"`
+			// DemoteAfterDays moves Playing games that haven't been played in
+			// this many days to DemoteTo. 0 never demotes
+			DemoteAfterDays int    `json:"demoteAfterDays"`
+			DemoteTo        string `json:"demoteTo"`
+		} `json:"recentlyPlayed"`
+	} `json:"steam"`
+	Notion struct {
+		// UserOwnedProperties are Games DB properties that syncs never
+		// overwrite, on top of Name, Status, Platform, Rating, Notes and
+		// Completed Date
+		UserOwnedProperties []string `json:"userOwnedProperties"`
+		// ArchiveRemovedWishlistGames archives Unowned and Unreleased games
+		// kanbanchan added that are no longer on the Steam wishlist
+		ArchiveRemovedWishlistGames bool `json:"archiveRemovedWishlistGames"`
+		// Properties maps databases (games, anime, movies, tv) to the fields
+		// kanbanchan keeps in them, like "Cover Art", and the name or ID of
+		// the property each is stored in. Fields that aren't mapped are
+		// stored in properties named after them
+		Properties map[string]map[string]string `json:"properties"`
+	} `json:"notion"`
+	TMDB struct {
+		// Region is the country code, like "GB", that watch providers are
+		// looked up in. "US" is used when empty
+		Region string `json:"region"`
+		// Language is the language titles and descriptions are requested in,
+		// like "en-US"
+		Language string `json:"language"`
+	} `json:"tmdb"`
+	// Transitions are rules that move games between statuses, evaluated in
+	// order. Released games move from Unreleased to Unowned when empty
+	Transitions []notion.Rule `json:"transitions"`
+	Serve       struct {
+		// Address is where the health endpoint listens, ":8080" when empty
+		Address string `json:"address"`
+		// StatePath is where job state is saved between restarts
+		StatePath string `json:"statePath"`
+		// Jobs maps job names (sync-games, sync-anime, sync-movies, sync-tv,
+		// transition, refresh-app-list) to cron expressions. Jobs without an
+		// expression don't run
+		Jobs map[string]string `json:"jobs"`
+	} `json:"serve"`
+}
+
+// Load reads the config at path, falling back to DefaultPath when empty
+func Load(path string) (*Config, error) {
+	var config Config
+	if path == "" {
+		path = DefaultPath
+	}
+	fileContent, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(fileContent, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rules are checked now rather than when a transition runs, so a typo
+	// doesn't fail partway through applying a plan
+	_, err = notion.NewTransitionEngine(config.Transitions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transitions in %s: %s", path, err.Error())
+	}
+	demoteTo := config.Steam.RecentlyPlayed.DemoteTo
+	if demoteTo != "" && !isGameStatus(demoteTo) {
+		return nil, fmt.Errorf("invalid steam.recentlyPlayed.demoteTo in %s: unknown status %s, expected one of %s",
+			path, demoteTo, strings.Join(notion.Games.Statuses, ", "))
+	}
+	return &config, nil
+}
+
+// isGameStatus reports whether status is one of the Games DB statuses
+func isGameStatus(status string) bool {
+	for _, known := range notion.Games.Statuses {
+		if known == status {
+			return true
+		}
+	}
+	return false
+}
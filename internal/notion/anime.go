@@ -0,0 +1,225 @@
+package notion
+
+import (
+	"fmt"
+	"kanbanchan/internal/anilist"
+	"kanbanchan/internal/clock"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+const StatusWatching = "Watching"
+
+// animeStatuses maps the AniList lists that are synced to Anime DB statuses
+var animeStatuses = map[string]string{
+	string(anilist.StatusWatching): StatusWatching,
+	string(anilist.StatusFinished): StatusFinished,
+	string(anilist.StatusPlanning): StatusUpNext,
+}
+
+// animeUserOwnedProperties are only written when an anime page is created.
+// Status follows the AniList list the anime is on
+var animeUserOwnedProperties = []string{"Name", "Rating", "Notes"}
+
+// AnimeProperties contains info about pages in the Anime database
+type AnimeProperties struct {
+	PageID        string                         `json:"pageID"`
+	Name          *notionapi.TitleProperty       `json:"name,omitempty"`
+	Status        *notionapi.StatusProperty      `json:"status,omitempty"`
+	AniListID     *notionapi.RichTextProperty    `json:"aniListID,omitempty"`
+	Progress      *notionapi.NumberProperty      `json:"progress,omitempty"`
+	Episodes      *notionapi.NumberProperty      `json:"episodes,omitempty"`
+	Score         *notionapi.NumberProperty      `json:"score,omitempty"`
+	Genres        *notionapi.MultiSelectProperty `json:"genres,omitempty"`
+	CompletedDate *notionapi.DateProperty        `json:"completedDate,omitempty"`
+	properties    notionapi.Properties           // every property on the page, for diffing
+}
+
+// Title returns the plain text title of an anime page
+func (ap AnimeProperties) Title() string {
+	if ap.Name == nil {
+		return ""
+	}
+	return plainText(ap.Name.Title)
+}
+
+// AniListIDValue returns the AniList ID an anime page is linked to, if any
+func (ap AnimeProperties) AniListIDValue() string {
+	if ap.AniListID == nil {
+		return ""
+	}
+	return strings.TrimSpace(plainText(ap.AniListID.RichText))
+}
+
+// GetAnimePages retrieves all pages in the Anime DB keyed by title. Only the
+// first page with a given title is kept; use ListAnimePages to get every page
+func (nc *NotionClient) GetAnimePages(options *notionapi.DatabaseQueryRequest) (*map[string]AnimeProperties, error) {
+	pages, err := nc.ListAnimePages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	anime := make(map[string]AnimeProperties)
+	for _, page := range pages {
+		_, ok := anime[page.Title()]
+		if !ok {
+			anime[page.Title()] = page
+		}
+	}
+	return &anime, nil
+}
+
+// ListAnimePages retrieves every page in the Anime DB
+func (nc *NotionClient) ListAnimePages(options *notionapi.DatabaseQueryRequest) ([]AnimeProperties, error) {
+	return nc.Anime().List(options)
+}
+
+// newAnimeProperties reads the properties of a page in the Anime DB
+func newAnimeProperties(page notionapi.Page) AnimeProperties {
+	ap := AnimeProperties{
+		PageID:     page.ID.String(),
+		properties: page.Properties,
+	}
+	ap.Name, _ = page.Properties["Name"].(*notionapi.TitleProperty)
+	ap.Status, _ = page.Properties["Status"].(*notionapi.StatusProperty)
+	ap.AniListID, _ = page.Properties["AniList ID"].(*notionapi.RichTextProperty)
+	ap.Progress, _ = page.Properties["Progress"].(*notionapi.NumberProperty)
+	ap.Episodes, _ = page.Properties["Episodes"].(*notionapi.NumberProperty)
+	ap.Score, _ = page.Properties["Score"].(*notionapi.NumberProperty)
+	ap.Genres, _ = page.Properties["Genres"].(*notionapi.MultiSelectProperty)
+	ap.CompletedDate, _ = page.Properties["Completed Date"].(*notionapi.DateProperty)
+	return ap
+}
+
+// AnimeIndex finds anime pages by AniList ID, falling back to the title for
+// pages that haven't been linked to AniList yet
+type AnimeIndex struct {
+	byID    map[string]AnimeProperties
+	byTitle map[string]AnimeProperties
+}
+
+// NewAnimeIndex indexes anime pages. Only the first page with a given ID or
+// unlinked title is kept
+func NewAnimeIndex(pages []AnimeProperties) *AnimeIndex {
+	index := AnimeIndex{
+		byID:    make(map[string]AnimeProperties),
+		byTitle: make(map[string]AnimeProperties),
+	}
+	for _, page := range pages {
+		if id := page.AniListIDValue(); id != "" {
+			if _, ok := index.byID[id]; !ok {
+				index.byID[id] = page
+			}
+			continue
+		}
+		title := strings.ToLower(strings.TrimSpace(page.Title()))
+		if _, ok := index.byTitle[title]; title != "" && !ok {
+			index.byTitle[title] = page
+		}
+	}
+	return &index
+}
+
+// Get returns the page for an anime, if there is one
+func (ai *AnimeIndex) Get(anime anilist.Anime) (AnimeProperties, bool) {
+	if page, ok := ai.byID[anime.ID]; ok {
+		return page, true
+	}
+	page, ok := ai.byTitle[strings.ToLower(strings.TrimSpace(anime.Title))]
+	return page, ok
+}
+
+// PlanAddAnime plans adding an anime to the Anime DB. Optional columns the
+// database doesn't have are left out
+func (nc *NotionClient) PlanAddAnime(anime anilist.Anime) (*Operation, error) {
+	properties := nc.aniListProperties(anime)
+	properties["Name"] = &notionapi.TitleProperty{
+		Title: []notionapi.RichText{{
+			Text:      &notionapi.Text{Content: anime.Title},
+			PlainText: anime.Title,
+		}},
+	}
+
+	op, err := nc.planPageCreate(Anime, anime.Title, properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add anime %s: %s", anime.Title, err.Error())
+	}
+	return op, nil
+}
+
+// PlanAnimeSync plans updating the properties of an existing anime page that
+// differ from AniList, leaving Name, Rating and Notes alone. Nil is returned
+// when the page is already up to date
+func (nc *NotionClient) PlanAnimeSync(existing AnimeProperties, anime anilist.Anime) (*Operation, error) {
+	kind := OperationUpdate
+	if existing.Status == nil || existing.Status.Status.Name != animeStatuses[string(anime.Status)] {
+		kind = OperationTransition
+	}
+	op, err := nc.planPageUpdate(kind, Anime, existing.PageID, existing.properties, nc.aniListProperties(anime), func(name string) bool {
+		return containsString(animeUserOwnedProperties, name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan %s of anime %s: %s", kind, existing.Title(), err.Error())
+	}
+	if op != nil {
+		op.Title = existing.Title()
+	}
+	return op, nil
+}
+
+// aniListProperties returns every property kanbanchan keeps in sync with
+// AniList for an anime. Optional properties are only included when AniList
+// has a value
+func (nc *NotionClient) aniListProperties(anime anilist.Anime) notionapi.Properties {
+	properties := notionapi.Properties{
+		"Status": &notionapi.StatusProperty{
+			Status: notionapi.Option{Name: animeStatuses[string(anime.Status)]},
+		},
+		"AniList ID":   &notionapi.RichTextProperty{RichText: richText(anime.ID)},
+		"AniList Page": &notionapi.URLProperty{URL: anime.SiteURL},
+		"Progress":     &notionapi.NumberProperty{Number: float64(anime.Progress)},
+		"Genres":       &notionapi.MultiSelectProperty{MultiSelect: selectOptions(anime.Genres)},
+	}
+	if anime.CoverImage != "" {
+		properties["Cover Art"] = &notionapi.FilesProperty{
+			Files: []notionapi.File{{
+				Name:     anime.CoverImage,
+				Type:     notionapi.FileTypeExternal,
+				External: &notionapi.FileObject{URL: anime.CoverImage},
+			}},
+		}
+	}
+	if anime.Episodes > 0 {
+		properties["Episodes"] = &notionapi.NumberProperty{Number: float64(anime.Episodes)}
+	}
+	if anime.Score > 0 {
+		properties["Score"] = &notionapi.NumberProperty{Number: anime.Score}
+	}
+	if anime.Format != "" {
+		properties["Format"] = &notionapi.SelectProperty{
+			Select: notionapi.Option{Name: strings.ReplaceAll(anime.Format, "_", " ")},
+		}
+	}
+	if anime.Season != "" {
+		properties["Season"] = &notionapi.RichTextProperty{RichText: richText(anime.Season)}
+	}
+	if !anime.StartedAt.IsZero() {
+		properties["Started Date"] = nc.dayProperty(anime.StartedAt)
+	}
+	if anime.Status == anilist.StatusFinished && !anime.CompletedAt.IsZero() {
+		properties["Completed Date"] = nc.dayProperty(anime.CompletedAt)
+	}
+	return properties
+}
+
+// dayProperty returns a date property for the calendar day t falls on,
+// written as midnight in the client's time zone. AniList dates are date-only,
+// midnight UTC, so they keep the day AniList names
+func (nc *NotionClient) dayProperty(t time.Time) *notionapi.DateProperty {
+	day := notionapi.Date(clock.Midnight(t, nc.clock))
+	return &notionapi.DateProperty{
+		Date: &notionapi.DateObject{Start: &day},
+	}
+}
@@ -1,10 +1,10 @@
 package notion
 
 import (
-	"context"
 	"fmt"
+	"kanbanchan/internal/clock"
 	"kanbanchan/internal/steam"
-	"os"
+	"math"
 	"strings"
 	"time"
 
@@ -30,213 +30,306 @@ type GameProperties struct {
 	ReleaseDate       *notionapi.DateProperty        `json:"releaseDate,omitempty"`
 	Rating            *notionapi.RichTextProperty    `json:"rating,omitempty"`
 	Notes             *notionapi.RichTextProperty    `json:"notes,omitempty"`
+	Achievements      *notionapi.RichTextProperty    `json:"achievements,omitempty"`
+	CompletionPercent *notionapi.NumberProperty      `json:"completionPercent,omitempty"`
+	LastPlayed        *notionapi.DateProperty        `json:"lastPlayed,omitempty"`
+	HoursPlayed       *notionapi.NumberProperty      `json:"hoursPlayed,omitempty"`
+	Installed         *notionapi.CheckboxProperty    `json:"installed,omitempty"`
+	Size              *notionapi.NumberProperty      `json:"size,omitempty"`
+	SteamAppID        *notionapi.RichTextProperty    `json:"steamAppID,omitempty"`
+	properties        notionapi.Properties           // every property on the page, for diffing
 }
 
-// GetGamePageByID fetches a single game page by its ID
+// GetGamePageByID fetches a single game page by its ID, with its properties
+// renamed to the fields they hold
 func (nc *NotionClient) GetGamePageByID(gameID string) (*notionapi.Page, error) {
-	page, err := nc.client.GetPageByID(context.Background(), gameID)
+	page, err := nc.client.GetPageByID(nc.ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve game id %s: %s", gameID, err.Error())
+	}
+	names, err := nc.propertyNames(Games)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve game id %s: %s", gameID, err.Error())
 	}
 
+	page.Properties = fromNotionProperties(page.Properties, names)
 	return page, nil
 }
 
-// GetGamePages retrieves all pages in the Games DB
+// GetGamePages retrieves all pages in the Games DB keyed by title. Only the
+// first page with a given title is kept; use ListGamePages to get every page
 func (nc *NotionClient) GetGamePages(options *notionapi.DatabaseQueryRequest) (*map[string]GameProperties, error) {
-	gameDB := nc.dbIDs.gameDB
-	env := os.Getenv("ENVIRONMENT")
-	if env == "development" || env == "dev" || env == "staging" || env == "local" {
-		gameDB = nc.dbIDs.testGame
-	}
-	options = setQueryOptions(options)
-	pages, err := nc.client.GetDatabasePages(gameDB, options)
+	pages, err := nc.ListGamePages(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get game pages from database id %s: %s", gameDB, err.Error())
+		return nil, err
 	}
 
 	games := make(map[string]GameProperties)
-	for _, page := range pages {
-		game := GameProperties{
-			PageID:            page.ID.String(),
-			Name:              page.Properties["Name"].(*notionapi.TitleProperty),
-			Status:            page.Properties["Status"].(*notionapi.StatusProperty),
-			Tags:              page.Properties["Tags"].(*notionapi.MultiSelectProperty),
-			OfficialStorePage: page.Properties["Official Store Page"].(*notionapi.URLProperty),
-			CompletedDate:     page.Properties["Completed Date"].(*notionapi.DateProperty),
-			CoverArt:          page.Properties["Cover Art"].(*notionapi.FilesProperty),
-			Platform:          page.Properties["Platform"].(*notionapi.MultiSelectProperty),
-			ReleaseDate:       page.Properties["Release Date"].(*notionapi.DateProperty),
-			Rating:            page.Properties["Rating"].(*notionapi.RichTextProperty),
-			Notes:             page.Properties["Notes"].(*notionapi.RichTextProperty),
-		}
-		_, ok := games[game.Name.Title[0].PlainText]
+	for _, game := range pages {
+		_, ok := games[game.Title()]
 		if !ok {
-			games[game.Name.Title[0].PlainText] = game
+			games[game.Title()] = game
 		}
 	}
 	return &games, nil
 }
 
-// AddGame adds a game to the Games DB
-func (nc *NotionClient) AddGame(game steam.SteamGame) error {
-	gameDB := nc.dbIDs.gameDB
-	env := os.Getenv("ENVIRONMENT")
-	if env == "development" || env == "dev" || env == "staging" || env == "local" {
-		gameDB = nc.dbIDs.testGame
+// ListGamePages retrieves every page in the Games DB
+func (nc *NotionClient) ListGamePages(options *notionapi.DatabaseQueryRequest) ([]GameProperties, error) {
+	return nc.Games().List(options)
+}
+
+// newGameProperties reads the properties of a page in the Games DB
+func newGameProperties(page notionapi.Page) GameProperties {
+	game := GameProperties{
+		PageID:     page.ID.String(),
+		properties: page.Properties,
 	}
+	// The repository checks the required columns exist before reading pages,
+	// and optional columns may not exist on every board
+	game.Name, _ = page.Properties["Name"].(*notionapi.TitleProperty)
+	game.Status, _ = page.Properties["Status"].(*notionapi.StatusProperty)
+	game.Tags, _ = page.Properties["Tags"].(*notionapi.MultiSelectProperty)
+	game.OfficialStorePage, _ = page.Properties["Official Store Page"].(*notionapi.URLProperty)
+	game.CompletedDate, _ = page.Properties["Completed Date"].(*notionapi.DateProperty)
+	game.CoverArt, _ = page.Properties["Cover Art"].(*notionapi.FilesProperty)
+	game.Platform, _ = page.Properties["Platform"].(*notionapi.MultiSelectProperty)
+	game.ReleaseDate, _ = page.Properties["Release Date"].(*notionapi.DateProperty)
+	game.Rating, _ = page.Properties["Rating"].(*notionapi.RichTextProperty)
+	game.Notes, _ = page.Properties["Notes"].(*notionapi.RichTextProperty)
+	game.Achievements, _ = page.Properties["Achievements"].(*notionapi.RichTextProperty)
+	game.CompletionPercent, _ = page.Properties["Completion %"].(*notionapi.NumberProperty)
+	game.LastPlayed, _ = page.Properties["Last Played"].(*notionapi.DateProperty)
+	game.HoursPlayed, _ = page.Properties["Hours Played"].(*notionapi.NumberProperty)
+	game.Installed, _ = page.Properties["Installed"].(*notionapi.CheckboxProperty)
+	game.Size, _ = page.Properties["Size"].(*notionapi.NumberProperty)
+	game.SteamAppID, _ = page.Properties["Steam App ID"].(*notionapi.RichTextProperty)
+	return game
+}
 
-	properties := notionapi.Properties{}
-	status := determineGameStatus(game)
+// UpdateGame updates the properties of a page in the Games DB. Optional
+// columns the database doesn't have are left out rather than failing the request
+func (nc *NotionClient) UpdateGame(gameID string, props notionapi.Properties) error {
+	return nc.Games().Update(gameID, props)
+}
 
-	var genres []notionapi.Option
-	for _, genre := range game.Genres {
-		genres = append(genres, notionapi.Option{Name: genre})
+// PlanGameActivity plans writing when and how much a game has been played to
+// its page, along with a new status when one is given. Nil is returned when
+// the page is already up to date
+func (nc *NotionClient) PlanGameActivity(existing GameProperties, activity steam.GameActivity, status string) (*Operation, error) {
+	props := playtimeProperties(activity.Playtime, activity.LastPlayed)
+	if status != "" && (existing.Status == nil || existing.Status.Status.Name != status) {
+		props["Status"] = &notionapi.StatusProperty{
+			Status: notionapi.Option{Name: status},
+		}
+		return nc.planUpdate(OperationTransition, existing, props, nil)
 	}
-	notionReleaseDate := notionapi.Date(game.ReleaseDate)
-
-	properties["Name"] = &notionapi.TitleProperty{
-		Title: []notionapi.RichText{{
-			Text: &notionapi.Text{
-				Content: game.Name,
-			},
-			PlainText: game.Name,
-		}},
+	return nc.planUpdate(OperationUpdate, existing, props, nil)
+}
+
+// RecentlyPlayedStatus returns the status Steam activity moves a game page
+// to, or "" when it stays put. Games played in the last two weeks move to
+// Playing unless they're Finished, and Playing games last played more than
+// demoteAfterDays days ago move to demoteTo. 0 days never demotes, and games
+// that have never been played aren't stale, so they stay Playing
+func RecentlyPlayedStatus(existing GameProperties, activity steam.GameActivity, demoteAfterDays int, demoteTo string, c clock.Clock) string {
+	current := ""
+	if existing.Status != nil {
+		current = existing.Status.Status.Name
 	}
-	properties["Status"] = &notionapi.StatusProperty{
-		Status: notionapi.Option{
-			Name: status,
-		},
+	if activity.PlayedRecently() {
+		if current != StatusPlaying && current != StatusFinished {
+			return StatusPlaying
+		}
+		return ""
 	}
-	properties["Platform"] = &notionapi.MultiSelectProperty{
-		MultiSelect: []notionapi.Option{{Name: "Steam"}, {Name: "kanbanchan"}},
+	if current == StatusPlaying && demoteAfterDays > 0 && !activity.LastPlayed.IsZero() &&
+		c.Now().Sub(activity.LastPlayed) > time.Duration(demoteAfterDays)*24*time.Hour {
+		return demoteTo
 	}
-	properties["Tags"] = &notionapi.MultiSelectProperty{
-		MultiSelect: genres,
+	return ""
+}
+
+// PlanLinkSteamApp plans recording a game page's Steam app ID, and setting its
+// Official Store Page when the page doesn't have one yet
+func (nc *NotionClient) PlanLinkSteamApp(existing GameProperties, appID string) (*Operation, error) {
+	props := notionapi.Properties{
+		"Steam App ID": &notionapi.RichTextProperty{RichText: richText(appID)},
 	}
-	properties["Official Store Page"] = &notionapi.URLProperty{URL: fmt.Sprintf("https://store.steampowered.com/app/%s", game.ID)}
-	properties["Cover Art"] = &notionapi.FilesProperty{
-		Files: []notionapi.File{{
-			Name: game.HeaderImage,
-			Type: notionapi.FileTypeExternal,
-			External: &notionapi.FileObject{
-				URL: game.HeaderImage,
-			},
-		}},
+	if existing.OfficialStorePage == nil || existing.OfficialStorePage.URL == "" {
+		props["Official Store Page"] = &notionapi.URLProperty{URL: steamStoreURL(appID)}
 	}
-	properties["Release Date"] = &notionapi.DateProperty{
-		Date: &notionapi.DateObject{
-			Start: &notionReleaseDate,
-		},
+	return nc.planUpdate(OperationUpdate, existing, props, nil)
+}
+
+func (nc *NotionClient) determineGameStatus(game steam.SteamGame) string {
+	upNextVal, upNextOk := game.Collections[steam.CollectionUpNext]
+	playingVal, playingOk := game.Collections[steam.CollectionPlaying]
+	finishedVal, finishedOk := game.Collections[steam.CollectionFinished]
+
+	if finishedOk && finishedVal {
+		return StatusFinished
+	} else if nc.settings.autoFinish && game.Achievements.Complete() {
+		return StatusFinished
+	} else if playingOk && playingVal {
+		return StatusPlaying
+	} else if upNextOk && upNextVal {
+		return StatusUpNext
+	} else if game.ComingSoon {
+		return StatusUnreleased
+	} else {
+		return StatusUnowned
 	}
+}
 
-	_, err := nc.client.CreatePage(&notionapi.PageCreateRequest{
-		Parent: notionapi.Parent{
-			DatabaseID: notionapi.DatabaseID(gameDB),
-		},
-		Properties: properties,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to add game %s to database id %s: %s", game.Name, gameDB, err.Error())
+// achievementProperties returns the achievement columns for a game, or none
+// when the game has no achievements
+func achievementProperties(game steam.SteamGame) notionapi.Properties {
+	props := notionapi.Properties{}
+	if game.Achievements == nil {
+		return props
+	}
+	props["Achievements"] = &notionapi.RichTextProperty{
+		RichText: richText(achievementsText(game.Achievements)),
 	}
+	props["Completion %"] = &notionapi.NumberProperty{
+		Number: math.Round(game.Achievements.Percent*10) / 10,
+	}
+	return props
+}
 
-	// gp := GameProperties{
-	// 	Name:              page.Properties["Name"].(*notionapi.TitleProperty),
-	// 	Status:            page.Properties["Status"].(*notionapi.StatusProperty),
-	// 	Tags:              page.Properties["Tags"].(*notionapi.MultiSelectProperty),
-	// 	OfficialStorePage: page.Properties["Official Store Page"].(*notionapi.URLProperty),
-	// 	CompletedDate:     page.Properties["Completed Date"].(*notionapi.DateProperty),
-	// 	CoverArt:          page.Properties["Cover Art"].(*notionapi.FilesProperty),
-	// 	Platform:          page.Properties["Platform"].(*notionapi.MultiSelectProperty),
-	// 	ReleaseDate:       page.Properties["Release Date"].(*notionapi.DateProperty),
-	// 	Rating:            page.Properties["Rating"].(*notionapi.RichTextProperty),
-	// 	Notes:             page.Properties["Notes"].(*notionapi.RichTextProperty),
-	// }
-	// PrintGameProperties(gp)
-
-	return nil
+// steamStoreURL returns the Steam store page for an app ID
+func steamStoreURL(appID string) string {
+	return fmt.Sprintf("https://store.steampowered.com/app/%s", appID)
 }
 
-func (nc *NotionClient) UpdateGame(gameID string, props notionapi.Properties) error {
-	opts := &notionapi.PageUpdateRequest{
-		Properties: props,
+// installProperties returns whether a game is installed and the disk space it
+// uses in GB, or nothing when the Steam client wasn't found to tell
+func installProperties(game steam.SteamGame) notionapi.Properties {
+	if !game.InstallStateKnown {
+		return notionapi.Properties{}
 	}
+	return notionapi.Properties{
+		"Installed": &notionapi.CheckboxProperty{Checkbox: game.Installed},
+		"Size":      &notionapi.NumberProperty{Number: sizeGB(game.SizeOnDisk)},
+	}
+}
 
-	_, err := nc.client.UpdatePage(context.Background(), gameID, opts)
-	if err != nil {
-		return fmt.Errorf("failed to update page id %s: %s", gameID, err.Error())
+// sizeGB converts bytes to gigabytes rounded to one decimal place
+func sizeGB(bytes int64) float64 {
+	return math.Round(float64(bytes)/1e9*10) / 10
+}
+
+// achievementsText formats achievement progress as "unlocked/total"
+func achievementsText(completion *steam.Completion) string {
+	if completion == nil {
+		return ""
 	}
+	return fmt.Sprintf("%d/%d", completion.Achieved, completion.Total)
+}
 
-	return nil
+// playtimeBuckets are the Playtime Bucket options from the least time
+// invested to the most, each holding playtimes under its limit
+var playtimeBuckets = []struct {
+	name  string
+	limit time.Duration
+}{
+	{"Unplayed", time.Minute},
+	{"Under 2h", 2 * time.Hour},
+	{"2-10h", 10 * time.Hour},
+	{"10-50h", 50 * time.Hour},
+	{"50-100h", 100 * time.Hour},
 }
 
-// PrintGameProperties prints the columns of a game from the Games DB in readable format
-func PrintGameProperties(gp GameProperties) {
-	builder := strings.Builder{}
+// playtimeBucketOver100 is the Playtime Bucket for everything else
+const playtimeBucketOver100 = "100h+"
 
-	builder.WriteString(fmt.Sprintf("Page ID: %s\n", gp.PageID))
-	builder.WriteString(fmt.Sprintf("Name: %s\n", gp.Name.Title[0].PlainText))
-	builder.WriteString(fmt.Sprintf("Status: %s\n", gp.Status.Status.Name))
-	if len(gp.Rating.RichText) != 0 {
-		builder.WriteString(fmt.Sprintf("Rating: %s\n", gp.Rating.RichText[0].PlainText))
-	} else {
-		builder.WriteString("Rating: <empty>\n")
-	}
-	var platforms []string
-	for _, platform := range gp.Platform.MultiSelect {
-		platforms = append(platforms, platform.Name)
-	}
-	builder.WriteString(fmt.Sprintf("Platforms: %s\n", strings.Join(platforms[:], ", ")))
-	var tags []string
-	for _, tag := range gp.Tags.MultiSelect {
-		tags = append(tags, tag.Name)
-	}
-	builder.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(tags[:], ", ")))
-	if gp.OfficialStorePage != nil {
-		builder.WriteString(fmt.Sprintf("Official Store Page: %s\n", gp.OfficialStorePage.URL))
-	} else {
-		builder.WriteString("Official Store Page: <empty>\n")
+// playtimeProperties returns the total and per-platform hours a game has been
+// played, the bucket its total falls in and when it was last played
+func playtimeProperties(playtime steam.Playtime, lastPlayed time.Time) notionapi.Properties {
+	props := notionapi.Properties{
+		"Hours Played":     &notionapi.NumberProperty{Number: playtimeHours(playtime.Total)},
+		"Windows Hours":    &notionapi.NumberProperty{Number: playtimeHours(playtime.Windows)},
+		"Mac Hours":        &notionapi.NumberProperty{Number: playtimeHours(playtime.Mac)},
+		"Linux Hours":      &notionapi.NumberProperty{Number: playtimeHours(playtime.Linux)},
+		"Steam Deck Hours": &notionapi.NumberProperty{Number: playtimeHours(playtime.Deck)},
+		"Playtime Bucket": &notionapi.SelectProperty{
+			Select: notionapi.Option{Name: playtimeBucket(playtime.Total)},
+		},
 	}
-	if len(gp.CoverArt.Files) != 0 {
-		builder.WriteString(fmt.Sprintf("Cover Art: %s\n", gp.CoverArt.Files[0].Name))
-	} else {
-		builder.WriteString("Cover Art: <empty>\n")
+	if !lastPlayed.IsZero() {
+		date := notionapi.Date(lastPlayed)
+		props["Last Played"] = &notionapi.DateProperty{
+			Date: &notionapi.DateObject{Start: &date},
+		}
 	}
-	if gp.ReleaseDate.Date != nil {
-		builder.WriteString(fmt.Sprintf("Release Date: %s\n", gp.ReleaseDate.Date.Start.String()))
-	} else {
-		builder.WriteString("Release Date: <empty>\n")
+	return props
+}
+
+// playtimeBucketNames returns every Playtime Bucket option in order
+func playtimeBucketNames() []string {
+	var names []string
+	for _, bucket := range playtimeBuckets {
+		names = append(names, bucket.name)
 	}
-	if gp.CompletedDate.Date != nil {
+	return append(names, playtimeBucketOver100)
+}
 
-		builder.WriteString(fmt.Sprintf("Completed Date: %s\n", gp.CompletedDate.Date.Start.String()))
-	} else {
-		builder.WriteString("Completed Date: <empty>\n")
+// playtimeBucket returns the Playtime Bucket a total playtime falls in
+func playtimeBucket(playtime time.Duration) string {
+	for _, bucket := range playtimeBuckets {
+		if playtime < bucket.limit {
+			return bucket.name
+		}
 	}
-	if len(gp.Notes.RichText) != 0 {
-		builder.WriteString(fmt.Sprintf("Notes: %s\n", gp.Notes.RichText[0].PlainText))
-	} else {
-		builder.WriteString("Notes: <empty>\n")
+	return playtimeBucketOver100
+}
+
+// playtimeHours converts playtime to hours rounded to one decimal place
+func playtimeHours(playtime time.Duration) float64 {
+	return math.Round(playtime.Hours()*10) / 10
+}
+
+// todayProperty returns a date property set to the start of the current day
+// in the client's time zone
+func (nc *NotionClient) todayProperty() *notionapi.DateProperty {
+	today := notionapi.Date(clock.Midnight(nc.clock.Now(), nc.clock))
+	return &notionapi.DateProperty{
+		Date: &notionapi.DateObject{Start: &today},
 	}
-	fmt.Print(builder.String())
 }
 
-func determineGameStatus(game steam.SteamGame) string {
-	upNextVal, upNextOk := game.Collections[steam.CollectionUpNext]
-	playingVal, playingOk := game.Collections[steam.CollectionPlaying]
-	finishedVal, finishedOk := game.Collections[steam.CollectionFinished]
+// GetGameDatabase retrieves the Games DB for the client's environment
+func (nc *NotionClient) GetGameDatabase() (*notionapi.Database, error) {
+	return nc.GetDatabase(nc.DatabaseID(Games))
+}
 
-	if finishedOk && finishedVal {
-		return StatusFinished
-	} else if playingOk && playingVal {
-		return StatusPlaying
-	} else if upNextOk && upNextVal {
-		return StatusUpNext
-	} else if game.ReleaseDate.Before(time.Now()) {
-		return StatusUnowned
-	} else {
-		return StatusUnreleased
+// selectOptions converts names to select options, dropping commas which
+// Notion doesn't allow in option names
+func selectOptions(names []string) []notionapi.Option {
+	options := []notionapi.Option{}
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ReplaceAll(name, ",", ""))
+		if name != "" {
+			options = append(options, notionapi.Option{Name: name})
+		}
+	}
+	return options
+}
+
+// richText wraps plain text as a Notion rich text value, truncated to the
+// 2000 characters Notion allows per text object
+func richText(content string) []notionapi.RichText {
+	if content == "" {
+		return []notionapi.RichText{}
+	}
+	if runes := []rune(content); len(runes) > 2000 {
+		content = string(runes[:2000])
 	}
+	return []notionapi.RichText{{
+		Text:      &notionapi.Text{Content: content},
+		PlainText: content,
+	}}
 }
 
 func setQueryOptions(options *notionapi.DatabaseQueryRequest) *notionapi.DatabaseQueryRequest {
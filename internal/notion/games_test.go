@@ -0,0 +1,54 @@
+package notion
+
+import (
+	"kanbanchan/internal/clock"
+	"kanbanchan/internal/steam"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestRecentlyPlayedStatus(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	now := time.Date(2025, 10, 16, 9, 0, 0, 0, newYork)
+	c := clock.Fixed(now)
+
+	recently := steam.GameActivity{LastPlayed: now.Add(-time.Hour), Playtime: steam.Playtime{Recent: time.Hour}}
+	playedAgo := func(d time.Duration) steam.GameActivity {
+		return steam.GameActivity{LastPlayed: now.Add(-d), Playtime: steam.Playtime{Total: 10 * time.Hour}}
+	}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		status    string
+		activity  steam.GameActivity
+		demoteAge int
+		want      string
+	}{
+		{name: "played recently promotes", status: StatusUpNext, activity: recently, demoteAge: 30, want: StatusPlaying},
+		{name: "played recently without a status promotes", status: "", activity: recently, demoteAge: 30, want: StatusPlaying},
+		{name: "played recently while playing stays", status: StatusPlaying, activity: recently, demoteAge: 30},
+		{name: "played recently while finished stays", status: StatusFinished, activity: recently, demoteAge: 30},
+		{name: "exactly at the threshold stays", status: StatusPlaying, activity: playedAgo(30 * day), demoteAge: 30},
+		{name: "just past the threshold demotes", status: StatusPlaying, activity: playedAgo(30*day + time.Second), demoteAge: 30, want: StatusUpNext},
+		{name: "within the threshold stays", status: StatusPlaying, activity: playedAgo(20 * day), demoteAge: 30},
+		{name: "never played stays", status: StatusPlaying, activity: steam.GameActivity{}, demoteAge: 30},
+		{name: "demoting disabled", status: StatusPlaying, activity: playedAgo(365 * day), demoteAge: 0},
+		{name: "not playing isn't demoted", status: StatusUpNext, activity: playedAgo(365 * day), demoteAge: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := GameProperties{}
+			if tt.status != "" {
+				game.Status = &notionapi.StatusProperty{Status: notionapi.Option{Name: tt.status}}
+			}
+			got := RecentlyPlayedStatus(game, tt.activity, tt.demoteAge, StatusUpNext, c)
+			if got != tt.want {
+				t.Errorf("RecentlyPlayedStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package notion
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// PlatformSteam identifies Steam app IDs in a GameIndex
+const PlatformSteam = "steam"
+
+var steamStoreURLPattern = regexp.MustCompile(`store\.steampowered\.com/app/(\d+)`)
+
+// GameIndex looks up game pages by platform and the platform's ID for the
+// game, so pages are matched even when their titles differ from the store's
+type GameIndex struct {
+	byID       map[string]GameProperties
+	unlinked   []GameProperties
+	duplicates []GameProperties
+}
+
+// NewGameIndex indexes game pages by their Steam App ID, falling back to the
+// app ID in the Official Store Page URL for pages that predate the Steam App
+// ID column. Pages without either are kept as unlinked
+func NewGameIndex(games []GameProperties) *GameIndex {
+	index := GameIndex{byID: make(map[string]GameProperties)}
+	for _, game := range games {
+		appID := game.SteamAppIDValue()
+		if appID == "" {
+			index.unlinked = append(index.unlinked, game)
+			continue
+		}
+		key := indexKey(PlatformSteam, appID)
+		if _, ok := index.byID[key]; ok {
+			index.duplicates = append(index.duplicates, game)
+			continue
+		}
+		index.byID[key] = game
+	}
+	return &index
+}
+
+// Get returns the game page for a platform's ID
+func (gi *GameIndex) Get(platform string, id string) (GameProperties, bool) {
+	game, ok := gi.byID[indexKey(platform, id)]
+	return game, ok
+}
+
+// RemovedWishlistGames returns the Unowned and Unreleased pages kanbanchan
+// added whose Steam app IDs aren't in current, the app IDs on Steam
+func (gi *GameIndex) RemovedWishlistGames(current map[string]bool) []GameProperties {
+	var removed []GameProperties
+	for key, game := range gi.byID {
+		if !strings.HasPrefix(key, PlatformSteam+":") || current[game.SteamAppIDValue()] {
+			continue
+		}
+		if game.Status == nil || (game.Status.Status.Name != StatusUnowned && game.Status.Status.Name != StatusUnreleased) {
+			continue
+		}
+		if game.Platform == nil || !hasOption(game.Platform.MultiSelect, "kanbanchan") {
+			continue
+		}
+		removed = append(removed, game)
+	}
+	sort.Slice(removed, func(a, b int) bool {
+		return removed[a].Title() < removed[b].Title()
+	})
+	return removed
+}
+
+// Unlinked returns the game pages with no platform ID
+func (gi *GameIndex) Unlinked() []GameProperties {
+	return gi.unlinked
+}
+
+// Duplicates returns game pages whose platform ID is already used by another page
+func (gi *GameIndex) Duplicates() []GameProperties {
+	return gi.duplicates
+}
+
+// SteamAppIDValue returns the game's Steam app ID from the Steam App ID column,
+// or parsed from the Official Store Page URL when that column is empty
+func (gp GameProperties) SteamAppIDValue() string {
+	if gp.SteamAppID != nil && len(gp.SteamAppID.RichText) != 0 {
+		if appID := strings.TrimSpace(gp.SteamAppID.RichText[0].PlainText); appID != "" {
+			return appID
+		}
+	}
+	if gp.OfficialStorePage != nil {
+		return SteamAppIDFromURL(gp.OfficialStorePage.URL)
+	}
+	return ""
+}
+
+// Title returns the plain text title of the game
+func (gp GameProperties) Title() string {
+	if gp.Name == nil || len(gp.Name.Title) == 0 {
+		return ""
+	}
+	return gp.Name.Title[0].PlainText
+}
+
+// SteamAppIDFromURL parses the app ID from a Steam store URL, returning an
+// empty string when the URL isn't a store page
+func SteamAppIDFromURL(url string) string {
+	match := steamStoreURLPattern.FindStringSubmatch(url)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func indexKey(platform string, id string) string {
+	return fmt.Sprintf("%s:%s", platform, strings.TrimSpace(id))
+}
+
+func hasOption(options []notionapi.Option, name string) bool {
+	for _, option := range options {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package notion
+
+import (
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// PropertySpec describes a column kanbanchan reads or writes
+type PropertySpec struct {
+	Name     string                       `json:"name"`
+	Type     notionapi.PropertyConfigType `json:"type"`
+	Required bool                         `json:"required,omitempty"` // every board must have it, others are written only when present
+	Options  []string                     `json:"options,omitempty"`  // a select's expected options; a Status property's are its kind's Statuses
+}
+
+// MediaKind describes one of the databases kanbanchan keeps: the columns it
+// reads and writes and the statuses its pages move between
+type MediaKind struct {
+	Name       string         `json:"name"`   // singular, like "game"
+	Plural     string         `json:"plural"` // like "games"
+	Properties []PropertySpec `json:"properties"`
+	Statuses   []string       `json:"statuses"`
+}
+
+// Games are tracked from Steam
+var Games = &MediaKind{
+	Name:   "game",
+	Plural: "games",
+	Properties: []PropertySpec{
+		{Name: "Name", Type: notionapi.PropertyConfigTypeTitle, Required: true},
+		{Name: "Status", Type: notionapi.PropertyConfigStatus, Required: true},
+		{Name: "Tags", Type: notionapi.PropertyConfigTypeMultiSelect, Required: true},
+		{Name: "Official Store Page", Type: notionapi.PropertyConfigTypeURL, Required: true},
+		{Name: "Completed Date", Type: notionapi.PropertyConfigTypeDate, Required: true},
+		{Name: "Cover Art", Type: notionapi.PropertyConfigTypeFiles, Required: true},
+		{Name: "Platform", Type: notionapi.PropertyConfigTypeMultiSelect, Required: true},
+		{Name: "Release Date", Type: notionapi.PropertyConfigTypeDate, Required: true},
+		{Name: "Rating", Type: notionapi.PropertyConfigTypeRichText, Required: true},
+		{Name: "Notes", Type: notionapi.PropertyConfigTypeRichText, Required: true},
+		{Name: "Steam App ID", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Release Window", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Developer", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Publisher", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Features", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "OS", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Description", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Metacritic", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Price", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Achievements", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Completion %", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Installed", Type: notionapi.PropertyConfigTypeCheckbox},
+		{Name: "Size", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Hours Played", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Windows Hours", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Mac Hours", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Linux Hours", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Steam Deck Hours", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Playtime Bucket", Type: notionapi.PropertyConfigTypeSelect, Options: playtimeBucketNames()},
+		{Name: "Last Played", Type: notionapi.PropertyConfigTypeDate},
+	},
+	Statuses: []string{StatusUnowned, StatusUnreleased, StatusUpNext, StatusPlaying, StatusFinished},
+}
+
+// Anime are tracked from AniList
+var Anime = &MediaKind{
+	Name:   "anime",
+	Plural: "anime",
+	Properties: []PropertySpec{
+		{Name: "Name", Type: notionapi.PropertyConfigTypeTitle, Required: true},
+		{Name: "Status", Type: notionapi.PropertyConfigStatus, Required: true},
+		{Name: "AniList ID", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "AniList Page", Type: notionapi.PropertyConfigTypeURL},
+		{Name: "Progress", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Episodes", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Score", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Genres", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Cover Art", Type: notionapi.PropertyConfigTypeFiles},
+		{Name: "Format", Type: notionapi.PropertyConfigTypeSelect},
+		{Name: "Season", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Started Date", Type: notionapi.PropertyConfigTypeDate},
+		{Name: "Completed Date", Type: notionapi.PropertyConfigTypeDate},
+	},
+	Statuses: []string{StatusUpNext, StatusWatching, StatusFinished},
+}
+
+// Movies are tracked from TMDB
+var Movies = &MediaKind{
+	Name:   "movie",
+	Plural: "movies",
+	Properties: []PropertySpec{
+		{Name: "Name", Type: notionapi.PropertyConfigTypeTitle, Required: true},
+		{Name: "Status", Type: notionapi.PropertyConfigStatus, Required: true},
+		{Name: "TMDB ID", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "TMDB Page", Type: notionapi.PropertyConfigTypeURL},
+		{Name: "Release Date", Type: notionapi.PropertyConfigTypeDate},
+		{Name: "Runtime", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Genres", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Director", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Where to Watch", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Description", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Poster", Type: notionapi.PropertyConfigTypeFiles},
+	},
+	Statuses: []string{StatusUpNext, StatusFinished},
+}
+
+// TV shows are tracked from TMDB, with progress recorded by episode
+var TV = &MediaKind{
+	Name:   "tv",
+	Plural: "tv",
+	Properties: []PropertySpec{
+		{Name: "Name", Type: notionapi.PropertyConfigTypeTitle, Required: true},
+		{Name: "Status", Type: notionapi.PropertyConfigStatus, Required: true},
+		{Name: "TMDB ID", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "TMDB Page", Type: notionapi.PropertyConfigTypeURL},
+		{Name: "Season", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Episode", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Seasons", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Episodes", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Episodes Left", Type: notionapi.PropertyConfigTypeNumber},
+		{Name: "Latest Episode", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Next Episode", Type: notionapi.PropertyConfigTypeDate},
+		{Name: "First Aired", Type: notionapi.PropertyConfigTypeDate},
+		{Name: "Completed Date", Type: notionapi.PropertyConfigTypeDate},
+		{Name: "Show Status", Type: notionapi.PropertyConfigTypeSelect},
+		{Name: "Genres", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Network", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Where to Watch", Type: notionapi.PropertyConfigTypeMultiSelect},
+		{Name: "Description", Type: notionapi.PropertyConfigTypeRichText},
+		{Name: "Poster", Type: notionapi.PropertyConfigTypeFiles},
+	},
+	Statuses: []string{StatusUpNext, StatusWatching, StatusWaitingForSeason, StatusFinished},
+}
+
+// mediaKinds lists every kind in the order commands report them
+var mediaKinds = []*MediaKind{Games, Anime, Movies, TV}
+
+// MediaKinds returns every kind of media kanbanchan keeps
+func MediaKinds() []*MediaKind {
+	return append([]*MediaKind{}, mediaKinds...)
+}
+
+// LookupMediaKind finds a kind by its singular or plural name
+func LookupMediaKind(name string) (*MediaKind, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, kind := range mediaKinds {
+		if name == kind.Name || name == kind.Plural {
+			return kind, true
+		}
+	}
+	return nil, false
+}
+
+// Property returns the spec of a column, if kanbanchan reads or writes it
+func (mk *MediaKind) Property(name string) (PropertySpec, bool) {
+	for _, spec := range mk.Properties {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return PropertySpec{}, false
+}
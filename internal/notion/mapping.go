@@ -0,0 +1,176 @@
+package notion
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// WithPropertyNames stores the fields of a kind of media in properties with
+// other names, for boards whose columns are named differently or in another
+// language. names maps fields, like "Official Store Page", to the name or ID
+// of the property holding them; IDs keep working when a property is renamed
+// in Notion. Fields that aren't mapped are stored in properties named after them
+func WithPropertyNames(kind *MediaKind, names map[string]string) ClientOption {
+	return func(nc *NotionClient) {
+		if nc.settings.propertyNames[kind] == nil {
+			nc.settings.propertyNames[kind] = make(map[string]string)
+		}
+		for field, name := range names {
+			if name = strings.TrimSpace(name); name != "" {
+				nc.settings.propertyNames[kind][field] = name
+			}
+		}
+	}
+}
+
+// propertyNames returns the name of the property each field of a kind of
+// media is stored in, keyed by field. Fields mapped to a property the
+// database doesn't have keep the name or ID they're mapped to, so they're
+// reported as missing
+func (nc *NotionClient) propertyNames(kind *MediaKind) (map[string]string, error) {
+	databaseID := nc.DatabaseID(kind)
+	schema, err := nc.databaseProperties(databaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	for _, spec := range kind.Properties {
+		names[spec.Name] = spec.Name
+	}
+	for field, target := range nc.settings.propertyNames[kind] {
+		names[field] = target
+		if _, ok := schema[target]; ok {
+			continue
+		}
+		ids, err := nc.databasePropertyIDs(databaseID)
+		if err != nil {
+			return nil, err
+		}
+		for name, id := range ids {
+			if samePropertyID(id, target) {
+				names[field] = name
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// mappedField reports whether a field of a kind of media is configured to be
+// stored in a property with another name or ID
+func (nc *NotionClient) mappedField(kind *MediaKind, field string) bool {
+	_, ok := nc.settings.propertyNames[kind][field]
+	return ok
+}
+
+// databasePropertyIDs returns the property IDs of a database keyed by
+// property name, fetching them only once per client
+func (nc *NotionClient) databasePropertyIDs(databaseID string) (map[string]string, error) {
+	nc.schemaMu.Lock()
+	defer nc.schemaMu.Unlock()
+	ids, ok := nc.propertyIDs[databaseID]
+	if ok {
+		return ids, nil
+	}
+
+	ids, err := nc.client.GetPropertyIDs(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	nc.propertyIDs[databaseID] = ids
+	return ids, nil
+}
+
+// toNotionProperties renames fields to the properties they're stored in
+func toNotionProperties(props notionapi.Properties, names map[string]string) notionapi.Properties {
+	renamed := notionapi.Properties{}
+	for field, prop := range props {
+		if name, ok := names[field]; ok {
+			renamed[name] = prop
+		} else {
+			renamed[field] = prop
+		}
+	}
+	return renamed
+}
+
+// toNotionSorts renames the fields sorts name to the properties they're
+// stored in
+func toNotionSorts(sorts []notionapi.SortObject, names map[string]string) []notionapi.SortObject {
+	renamed := make([]notionapi.SortObject, len(sorts))
+	for i, sort := range sorts {
+		if name, ok := names[sort.Property]; ok {
+			sort.Property = name
+		}
+		renamed[i] = sort
+	}
+	return renamed
+}
+
+// toNotionFilter renames the fields a filter names, and those of the filters
+// nested in and/or filters, to the properties they're stored in. Timestamp
+// filters don't name a property and are left as they are
+func toNotionFilter(filter notionapi.Filter, names map[string]string) notionapi.Filter {
+	switch f := filter.(type) {
+	case notionapi.PropertyFilter:
+		if name, ok := names[f.Property]; ok {
+			f.Property = name
+		}
+		return f
+	case *notionapi.PropertyFilter:
+		if f == nil {
+			return filter
+		}
+		renamed := *f
+		if name, ok := names[f.Property]; ok {
+			renamed.Property = name
+		}
+		return &renamed
+	case notionapi.AndCompoundFilter:
+		renamed := make(notionapi.AndCompoundFilter, len(f))
+		for i, nested := range f {
+			renamed[i] = toNotionFilter(nested, names)
+		}
+		return renamed
+	case notionapi.OrCompoundFilter:
+		renamed := make(notionapi.OrCompoundFilter, len(f))
+		for i, nested := range f {
+			renamed[i] = toNotionFilter(nested, names)
+		}
+		return renamed
+	}
+	return filter
+}
+
+// fromNotionProperties renames the properties of a page to the fields they
+// hold. Properties that don't hold a field keep their names, unless a field
+// stored elsewhere has the same name
+func fromNotionProperties(props notionapi.Properties, names map[string]string) notionapi.Properties {
+	fields := make(map[string]string, len(names))
+	for field, name := range names {
+		fields[name] = field
+	}
+	renamed := notionapi.Properties{}
+	for name, prop := range props {
+		if field, ok := fields[name]; ok {
+			renamed[field] = prop
+		} else if _, ok := names[name]; !ok {
+			renamed[name] = prop
+		}
+	}
+	return renamed
+}
+
+// samePropertyID reports whether two property IDs are the same, whether or
+// not they're URL-encoded the way Notion returns them
+func samePropertyID(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	unescapedA, errA := url.PathUnescape(a)
+	unescapedB, errB := url.PathUnescape(b)
+	return errA == nil && errB == nil && unescapedA == unescapedB
+}
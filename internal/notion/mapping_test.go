@@ -0,0 +1,190 @@
+package notion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestToNotionFilter(t *testing.T) {
+	names := map[string]string{"Status": "Stage", "Hours Played": "Hours"}
+	status := &notionapi.StatusFilterCondition{Equals: StatusPlaying}
+	hours := &notionapi.NumberFilterCondition{IsNotEmpty: true}
+	edited := notionapi.TimestampFilter{Timestamp: notionapi.TimestampLastEdited}
+
+	tests := []struct {
+		name   string
+		filter notionapi.Filter
+		want   notionapi.Filter
+	}{
+		{
+			name:   "property filter",
+			filter: notionapi.PropertyFilter{Property: "Status", Status: status},
+			want:   notionapi.PropertyFilter{Property: "Stage", Status: status},
+		},
+		{
+			name:   "property filter pointer",
+			filter: &notionapi.PropertyFilter{Property: "Status", Status: status},
+			want:   &notionapi.PropertyFilter{Property: "Stage", Status: status},
+		},
+		{
+			name:   "unmapped property",
+			filter: notionapi.PropertyFilter{Property: "Notes", RichText: &notionapi.TextFilterCondition{IsNotEmpty: true}},
+			want:   notionapi.PropertyFilter{Property: "Notes", RichText: &notionapi.TextFilterCondition{IsNotEmpty: true}},
+		},
+		{
+			name: "nested and/or filters",
+			filter: notionapi.AndCompoundFilter{
+				notionapi.PropertyFilter{Property: "Status", Status: status},
+				notionapi.OrCompoundFilter{
+					notionapi.PropertyFilter{Property: "Hours Played", Number: hours},
+					edited,
+				},
+			},
+			want: notionapi.AndCompoundFilter{
+				notionapi.PropertyFilter{Property: "Stage", Status: status},
+				notionapi.OrCompoundFilter{
+					notionapi.PropertyFilter{Property: "Hours", Number: hours},
+					edited,
+				},
+			},
+		},
+		{
+			name:   "timestamp filter",
+			filter: edited,
+			want:   edited,
+		},
+		{
+			name:   "no filter",
+			filter: nil,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toNotionFilter(tt.filter, names)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toNotionFilter() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToNotionFilterLeavesQueryAlone(t *testing.T) {
+	names := map[string]string{"Status": "Stage"}
+	filter := &notionapi.PropertyFilter{Property: "Status"}
+	toNotionFilter(notionapi.AndCompoundFilter{filter}, names)
+	if filter.Property != "Status" {
+		t.Errorf("toNotionFilter() renamed the caller's filter to %s", filter.Property)
+	}
+}
+
+// mappedClient returns a client whose Games DB has already been fetched, with
+// Status renamed to Stage and Hours Played stored in Time Played by its ID
+func mappedClient() *NotionClient {
+	nc := &NotionClient{
+		databases: map[*MediaKind]databaseIDs{Games: {production: "games-db"}},
+		schemas: map[string]notionapi.PropertyConfigs{
+			"games-db": {
+				"Name":        &notionapi.TitlePropertyConfig{},
+				"Stage":       &notionapi.StatusPropertyConfig{},
+				"Status":      &notionapi.SelectPropertyConfig{}, // an old column that holds no field
+				"Time Played": &notionapi.NumberPropertyConfig{},
+			},
+		},
+		propertyIDs: map[string]map[string]string{
+			"games-db": {"Name": "title", "Stage": "a%3Bb", "Status": "xyz", "Time Played": "%3DvXq"},
+		},
+	}
+	nc.settings.propertyNames = make(map[*MediaKind]map[string]string)
+	WithPropertyNames(Games, map[string]string{
+		"Status":       "Stage",
+		"Hours Played": "=vXq",
+		"Notes":        "Review",
+	})(nc)
+	return nc
+}
+
+func TestPropertyNames(t *testing.T) {
+	names, err := mappedClient().propertyNames(Games)
+	if err != nil {
+		t.Fatalf("propertyNames() error = %s", err.Error())
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "Name", want: "Name"},                // not mapped
+		{field: "Status", want: "Stage"},             // renamed
+		{field: "Hours Played", want: "Time Played"}, // resolved through its ID
+		{field: "Notes", want: "Review"},             // mapped to a property the database doesn't have
+		{field: "Last Played", want: "Last Played"},  // not mapped, and not in the database
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := names[tt.field]; got != tt.want {
+				t.Errorf("propertyNames()[%q] = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMappedProperties(t *testing.T) {
+	names, err := mappedClient().propertyNames(Games)
+	if err != nil {
+		t.Fatalf("propertyNames() error = %s", err.Error())
+	}
+	name := &notionapi.TitleProperty{Title: []notionapi.RichText{{PlainText: "Portal 2"}}}
+	stage := &notionapi.StatusProperty{Status: notionapi.Option{Name: StatusPlaying}}
+	oldStatus := &notionapi.SelectProperty{Select: notionapi.Option{Name: "Backlog"}}
+	hours := &notionapi.NumberProperty{Number: 12.5}
+	extra := &notionapi.CheckboxProperty{Checkbox: true}
+
+	fields := notionapi.Properties{"Name": name, "Status": stage, "Hours Played": hours}
+	page := notionapi.Properties{"Name": name, "Stage": stage, "Time Played": hours}
+
+	got := toNotionProperties(fields, names)
+	if !reflect.DeepEqual(got, page) {
+		t.Errorf("toNotionProperties() = %v, want %v", got, page)
+	}
+
+	// A property named after a field stored elsewhere is dropped, so it can't
+	// be mistaken for the field, while other properties keep their names
+	page["Status"] = oldStatus
+	page["Favorite"] = extra
+	want := notionapi.Properties{"Name": name, "Status": stage, "Hours Played": hours, "Favorite": extra}
+	got = fromNotionProperties(page, names)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fromNotionProperties() = %v, want %v", got, want)
+	}
+}
+
+func TestToNotionSorts(t *testing.T) {
+	names, err := mappedClient().propertyNames(Games)
+	if err != nil {
+		t.Fatalf("propertyNames() error = %s", err.Error())
+	}
+	sorts := []notionapi.SortObject{
+		{Property: "Hours Played", Direction: notionapi.SortOrderDESC},
+		{Property: "Status", Direction: notionapi.SortOrderASC},
+		{Property: "Name", Direction: notionapi.SortOrderASC},
+		{Timestamp: notionapi.TimestampCreated, Direction: notionapi.SortOrderDESC},
+	}
+	want := []notionapi.SortObject{
+		{Property: "Time Played", Direction: notionapi.SortOrderDESC},
+		{Property: "Stage", Direction: notionapi.SortOrderASC},
+		{Property: "Name", Direction: notionapi.SortOrderASC},
+		{Timestamp: notionapi.TimestampCreated, Direction: notionapi.SortOrderDESC},
+	}
+
+	got := toNotionSorts(sorts, names)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toNotionSorts() = %v, want %v", got, want)
+	}
+	if sorts[0].Property != "Hours Played" {
+		t.Errorf("toNotionSorts() renamed the caller's sort to %s", sorts[0].Property)
+	}
+}
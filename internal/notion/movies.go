@@ -0,0 +1,179 @@
+package notion
+
+import (
+	"fmt"
+	"kanbanchan/internal/tmdb"
+	"math"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// movieUserOwnedProperties are only written when a movie page is created, so
+// edits made in Notion are never overwritten by a sync
+var movieUserOwnedProperties = []string{"Name", "Status", "Rating", "Notes"}
+
+// MovieProperties contains info about pages in the Movies database
+type MovieProperties struct {
+	PageID      string                         `json:"pageID"`
+	Name        *notionapi.TitleProperty       `json:"name,omitempty"`
+	Status      *notionapi.StatusProperty      `json:"status,omitempty"`
+	TMDBID      *notionapi.RichTextProperty    `json:"tmdbID,omitempty"`
+	ReleaseDate *notionapi.DateProperty        `json:"releaseDate,omitempty"`
+	Runtime     *notionapi.NumberProperty      `json:"runtime,omitempty"`
+	Genres      *notionapi.MultiSelectProperty `json:"genres,omitempty"`
+	Director    *notionapi.MultiSelectProperty `json:"director,omitempty"`
+	Poster      *notionapi.FilesProperty       `json:"poster,omitempty"`
+	properties  notionapi.Properties           // every property on the page, for diffing
+}
+
+// Title returns the plain text title of a movie page
+func (mp MovieProperties) Title() string {
+	if mp.Name == nil {
+		return ""
+	}
+	return plainText(mp.Name.Title)
+}
+
+// TMDBIDValue returns the TMDB ID a movie page is linked to, if any
+func (mp MovieProperties) TMDBIDValue() string {
+	if mp.TMDBID == nil {
+		return ""
+	}
+	return strings.TrimSpace(plainText(mp.TMDBID.RichText))
+}
+
+// ReleaseYear returns the year of a movie page's release date, or 0 when it
+// doesn't have one
+func (mp MovieProperties) ReleaseYear() int {
+	releaseDate, ok := dateStart(mp.ReleaseDate)
+	if !ok {
+		return 0
+	}
+	return releaseDate.Year()
+}
+
+// GetMoviePages retrieves all pages in the Movies DB keyed by title. Only the
+// first page with a given title is kept; use ListMoviePages to get every page
+func (nc *NotionClient) GetMoviePages(options *notionapi.DatabaseQueryRequest) (*map[string]MovieProperties, error) {
+	pages, err := nc.ListMoviePages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	movies := make(map[string]MovieProperties)
+	for _, page := range pages {
+		_, ok := movies[page.Title()]
+		if !ok {
+			movies[page.Title()] = page
+		}
+	}
+	return &movies, nil
+}
+
+// ListMoviePages retrieves every page in the Movies DB
+func (nc *NotionClient) ListMoviePages(options *notionapi.DatabaseQueryRequest) ([]MovieProperties, error) {
+	return nc.Movies().List(options)
+}
+
+// newMovieProperties reads the properties of a page in the Movies DB
+func newMovieProperties(page notionapi.Page) MovieProperties {
+	mp := MovieProperties{
+		PageID:     page.ID.String(),
+		properties: page.Properties,
+	}
+	mp.Name, _ = page.Properties["Name"].(*notionapi.TitleProperty)
+	mp.Status, _ = page.Properties["Status"].(*notionapi.StatusProperty)
+	mp.TMDBID, _ = page.Properties["TMDB ID"].(*notionapi.RichTextProperty)
+	mp.ReleaseDate, _ = page.Properties["Release Date"].(*notionapi.DateProperty)
+	mp.Runtime, _ = page.Properties["Runtime"].(*notionapi.NumberProperty)
+	mp.Genres, _ = page.Properties["Genres"].(*notionapi.MultiSelectProperty)
+	mp.Director, _ = page.Properties["Director"].(*notionapi.MultiSelectProperty)
+	mp.Poster, _ = page.Properties["Poster"].(*notionapi.FilesProperty)
+	return mp
+}
+
+// FindMoviePage returns the page for a movie by TMDB ID, falling back to the
+// title for pages that haven't been linked to TMDB yet
+func FindMoviePage(pages []MovieProperties, movie tmdb.Movie) (MovieProperties, bool) {
+	for _, page := range pages {
+		if page.TMDBIDValue() == movie.ID {
+			return page, true
+		}
+	}
+	for _, page := range pages {
+		if page.TMDBIDValue() == "" && strings.EqualFold(strings.TrimSpace(page.Title()), strings.TrimSpace(movie.Title)) {
+			return page, true
+		}
+	}
+	return MovieProperties{}, false
+}
+
+// PlanAddMovie plans adding a movie to the Movies DB. Optional columns the
+// database doesn't have are left out
+func (nc *NotionClient) PlanAddMovie(movie tmdb.Movie) (*Operation, error) {
+	properties := nc.tmdbMovieProperties(movie)
+	properties["Name"] = &notionapi.TitleProperty{
+		Title: []notionapi.RichText{{
+			Text:      &notionapi.Text{Content: movie.Title},
+			PlainText: movie.Title,
+		}},
+	}
+
+	op, err := nc.planPageCreate(Movies, movie.Title, properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add movie %s: %s", movie.Title, err.Error())
+	}
+	return op, nil
+}
+
+// PlanMovieSync plans updating the properties of an existing movie page that
+// differ from TMDB, leaving Name, Status, Rating and Notes alone. Nil is
+// returned when the page is already up to date
+func (nc *NotionClient) PlanMovieSync(existing MovieProperties, movie tmdb.Movie) (*Operation, error) {
+	op, err := nc.planPageUpdate(OperationUpdate, Movies, existing.PageID, existing.properties, nc.tmdbMovieProperties(movie), func(name string) bool {
+		return containsString(movieUserOwnedProperties, name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan update of movie %s: %s", existing.Title(), err.Error())
+	}
+	if op != nil {
+		op.Title = existing.Title()
+	}
+	return op, nil
+}
+
+// UpdateMovie updates the properties of a page in the Movies DB. Optional
+// columns the database doesn't have are left out rather than failing the request
+func (nc *NotionClient) UpdateMovie(movieID string, props notionapi.Properties) error {
+	return nc.Movies().Update(movieID, props)
+}
+
+// tmdbMovieProperties returns every property kanbanchan keeps in sync with
+// TMDB for a movie. Optional properties are only included when TMDB has a value
+func (nc *NotionClient) tmdbMovieProperties(movie tmdb.Movie) notionapi.Properties {
+	properties := notionapi.Properties{
+		"TMDB ID":        &notionapi.RichTextProperty{RichText: richText(movie.ID)},
+		"TMDB Page":      &notionapi.URLProperty{URL: movie.URL},
+		"Genres":         &notionapi.MultiSelectProperty{MultiSelect: selectOptions(movie.Genres)},
+		"Director":       &notionapi.MultiSelectProperty{MultiSelect: selectOptions(movie.Directors)},
+		"Where to Watch": &notionapi.MultiSelectProperty{MultiSelect: selectOptions(movie.WatchProviders)},
+		"Description":    &notionapi.RichTextProperty{RichText: richText(movie.Overview)},
+	}
+	if movie.PosterURL != "" {
+		properties["Poster"] = &notionapi.FilesProperty{
+			Files: []notionapi.File{{
+				Name:     movie.PosterURL,
+				Type:     notionapi.FileTypeExternal,
+				External: &notionapi.FileObject{URL: movie.PosterURL},
+			}},
+		}
+	}
+	if movie.Runtime > 0 {
+		properties["Runtime"] = &notionapi.NumberProperty{Number: math.Round(movie.Runtime.Minutes())}
+	}
+	if !movie.ReleaseDate.IsZero() {
+		properties["Release Date"] = nc.dayProperty(movie.ReleaseDate)
+	}
+	return properties
+}
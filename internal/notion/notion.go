@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"kanbanchan/internal/aws"
+	"kanbanchan/internal/clock"
 	"kanbanchan/pkg/notion"
+	"strings"
+	"sync"
 
 	"github.com/jomei/notionapi"
 )
@@ -14,26 +17,57 @@ const (
 	StatusFinished = "Finished"
 )
 
+// Environments select which databases a client reads and writes. Production
+// uses the real databases and every other environment uses the test ones
+const (
+	EnvProduction  = "production"
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvLocal       = "local"
+)
+
+// environments maps accepted environment names, including short forms, to
+// whether they use the test databases
+var environments = map[string]bool{
+	EnvProduction:  false,
+	"prod":         false,
+	EnvDevelopment: true,
+	"dev":          true,
+	EnvStaging:     true,
+	EnvLocal:       true,
+}
+
 // NotionClient contains a usable Notion client and information about
 // databases in the workspace
 type NotionClient struct {
-	client    notion.NotionClient
-	workspace string
-	dbIDs     struct {
-		gameDB    string
-		animeDB   string
-		movieDB   string
-		tvDB      string
-		testGame  string
-		testAnime string
-		testMovie string
-		testTV    string
+	ctx         context.Context
+	client      notion.NotionClient
+	workspace   string
+	clock       clock.Clock
+	schemaMu    sync.Mutex
+	schemas     map[string]notionapi.PropertyConfigs
+	propertyIDs map[string]map[string]string
+	settings    struct {
+		autoFinish    bool
+		userOwned     map[string]bool
+		testDBs       bool
+		propertyNames map[*MediaKind]map[string]string // fields stored in other properties
 	}
+	databases map[*MediaKind]databaseIDs
 }
 
+// databaseIDs are the production and test databases of a kind of media
+type databaseIDs struct {
+	production string
+	test       string
+}
+
+// ClientOption configures optional settings on a NotionClient
+type ClientOption func(*NotionClient)
+
 // NewClient sets up an authenticated Notion client and user information about
 // databases in the workspace
-func NewClient(ctx context.Context) (*NotionClient, error) {
+func NewClient(ctx context.Context, opts ...ClientOption) (*NotionClient, error) {
 	var client NotionClient
 	var secrets, err = aws.GetSecrets()
 	if err != nil {
@@ -45,20 +79,84 @@ func NewClient(ctx context.Context) (*NotionClient, error) {
 		return nil, fmt.Errorf("failed to create notion client: %s", err.Error())
 	}
 
+	client.ctx = ctx
+	if client.ctx == nil {
+		client.ctx = context.Background()
+	}
 	client.client = *notionClient
+	client.clock = clock.New(nil)
+	client.schemas = make(map[string]notionapi.PropertyConfigs)
+	client.propertyIDs = make(map[string]map[string]string)
 	client.workspace = secrets.Notion.Workspace
-	client.dbIDs.gameDB = secrets.Notion.GameDB
-	client.dbIDs.animeDB = secrets.Notion.AnimeDB
-	client.dbIDs.movieDB = secrets.Notion.MovieDB
-	client.dbIDs.tvDB = secrets.Notion.TVDB
-	client.dbIDs.testGame = secrets.Notion.TestGame
-	client.dbIDs.testAnime = secrets.Notion.TestAnime
-	client.dbIDs.testMovie = secrets.Notion.TestMovie
-	client.dbIDs.testTV = secrets.Notion.TestTV
+	client.databases = map[*MediaKind]databaseIDs{
+		Games:  {production: secrets.Notion.GameDB, test: secrets.Notion.TestGame},
+		Anime:  {production: secrets.Notion.AnimeDB, test: secrets.Notion.TestAnime},
+		Movies: {production: secrets.Notion.MovieDB, test: secrets.Notion.TestMovie},
+		TV:     {production: secrets.Notion.TVDB, test: secrets.Notion.TestTV},
+	}
+	client.settings.userOwned = make(map[string]bool)
+	client.settings.propertyNames = make(map[*MediaKind]map[string]string)
+	for _, name := range defaultUserOwnedProperties {
+		client.settings.userOwned[name] = true
+	}
+
+	for _, opt := range opts {
+		opt(&client)
+	}
 
 	return &client, nil
 }
 
+// WithAutoFinish moves games with every achievement unlocked to Finished
+func WithAutoFinish(enabled bool) ClientOption {
+	return func(nc *NotionClient) {
+		nc.settings.autoFinish = enabled
+	}
+}
+
+// WithClock tells the time with c, which dates written to pages and release
+// dates are compared in. Clients use the system clock in the local time zone
+// by default
+func WithClock(c clock.Clock) ClientOption {
+	return func(nc *NotionClient) {
+		nc.clock = c
+	}
+}
+
+// Clock returns the clock the client tells the time with
+func (nc *NotionClient) Clock() clock.Clock {
+	return nc.clock
+}
+
+// ValidEnvironment reports whether env is an environment WithEnvironment accepts
+func ValidEnvironment(env string) bool {
+	_, ok := environments[strings.ToLower(env)]
+	return ok
+}
+
+// UsesTestDatabases reports whether env reads and writes the test databases
+func UsesTestDatabases(env string) bool {
+	return environments[strings.ToLower(env)]
+}
+
+// WithEnvironment reads and writes the test databases for every environment
+// other than production. Clients use the production databases by default
+func WithEnvironment(env string) ClientOption {
+	return func(nc *NotionClient) {
+		nc.settings.testDBs = UsesTestDatabases(env)
+	}
+}
+
+// DatabaseID returns the database of a kind of media, or its test database
+// outside of production
+func (nc *NotionClient) DatabaseID(kind *MediaKind) string {
+	ids := nc.databases[kind]
+	if nc.settings.testDBs {
+		return ids.test
+	}
+	return ids.production
+}
+
 // GetDatabase retrieves the specified database
 func (nc *NotionClient) GetDatabase(databaseID string) (*notionapi.Database, error) {
 	db, err := nc.client.GetDatabase(databaseID)
@@ -68,3 +166,110 @@ func (nc *NotionClient) GetDatabase(databaseID string) (*notionapi.Database, err
 
 	return db, nil
 }
+
+// databaseProperties returns the property configs of a database, fetching
+// them only once per client
+func (nc *NotionClient) databaseProperties(databaseID string) (notionapi.PropertyConfigs, error) {
+	nc.schemaMu.Lock()
+	defer nc.schemaMu.Unlock()
+	schema, ok := nc.schemas[databaseID]
+	if ok {
+		return schema, nil
+	}
+
+	db, err := nc.GetDatabase(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	nc.schemas[databaseID] = db.Properties
+	return db.Properties, nil
+}
+
+// pruneProperties drops the fields of a kind of media its database doesn't
+// have a property for, so optional columns can be written without every board
+// needing to add them first
+func (nc *NotionClient) pruneProperties(kind *MediaKind, props notionapi.Properties) (notionapi.Properties, error) {
+	schema, err := nc.databaseProperties(nc.DatabaseID(kind))
+	if err != nil {
+		return nil, err
+	}
+	names, err := nc.propertyNames(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := notionapi.Properties{}
+	for field, prop := range props {
+		name, ok := names[field]
+		if !ok {
+			name = field
+		}
+		if _, ok := schema[name]; ok {
+			pruned[field] = prop
+		}
+	}
+	return pruned, nil
+}
+
+// updatePage updates the fields of a page of a kind of media, leaving out
+// those its database doesn't have a property for
+func (nc *NotionClient) updatePage(kind *MediaKind, pageID string, props notionapi.Properties) error {
+	props, err := nc.pruneProperties(kind, props)
+	if err != nil {
+		return fmt.Errorf("failed to update page id %s: %s", pageID, err.Error())
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	names, err := nc.propertyNames(kind)
+	if err != nil {
+		return fmt.Errorf("failed to update page id %s: %s", pageID, err.Error())
+	}
+	opts := &notionapi.PageUpdateRequest{
+		Properties: toNotionProperties(props, names),
+	}
+
+	_, err = nc.client.UpdatePage(nc.ctx, pageID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to update page id %s: %s", pageID, err.Error())
+	}
+
+	return nil
+}
+
+// createPage creates a page of a kind of media, leaving out the fields its
+// database doesn't have a property for. The page is returned with its
+// properties renamed to the fields they hold
+func (nc *NotionClient) createPage(kind *MediaKind, props notionapi.Properties) (*notionapi.Page, error) {
+	props, err := nc.pruneProperties(kind, props)
+	if err != nil {
+		return nil, err
+	}
+	names, err := nc.propertyNames(kind)
+	if err != nil {
+		return nil, err
+	}
+	page, err := nc.client.CreatePage(&notionapi.PageCreateRequest{
+		Parent: notionapi.Parent{
+			DatabaseID: notionapi.DatabaseID(nc.DatabaseID(kind)),
+		},
+		Properties: toNotionProperties(props, names),
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Properties = fromNotionProperties(page.Properties, names)
+	return page, nil
+}
+
+// archivePage moves a page to the trash
+func (nc *NotionClient) archivePage(pageID string) error {
+	_, err := nc.client.UpdatePage(nc.ctx, pageID, &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{},
+		Archived:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive page id %s: %s", pageID, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"kanbanchan/internal/clock"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jomei/notionapi"
+)
+
+// OperationKind is what an Operation does to a page
+type OperationKind string
+
+const (
+	OperationCreate     OperationKind = "create"
+	OperationUpdate     OperationKind = "update"
+	OperationArchive    OperationKind = "archive"
+	OperationTransition OperationKind = "transition"
+)
+
+// Operation is a single planned write to a page in one of the databases
+type Operation struct {
+	Kind       OperationKind        `json:"kind"`
+	PageID     string               `json:"pageID,omitempty"`
+	Title      string               `json:"title"`
+	Reason     string               `json:"reason,omitempty"` // the rule behind a transition
+	Changes    []PropertyChange     `json:"changes,omitempty"`
+	media      *MediaKind           // the database the page is in
+	properties notionapi.Properties // written by ApplyPlan
+}
+
+// Plan is a list of writes to the databases that can be reviewed before any
+// of them are applied
+type Plan struct {
+	Operations []Operation `json:"operations"`
+}
+
+// Add appends an operation to the plan, ignoring nil operations so planners
+// that found nothing to change can be added unconditionally
+func (p *Plan) Add(op *Operation) {
+	if op != nil {
+		p.Operations = append(p.Operations, *op)
+	}
+}
+
+// Merge appends every operation of other to the plan. Updates and
+// transitions of a page the plan already updates are folded into its
+// operation instead, so each page is written once. Properties the plan
+// already writes take precedence
+func (p *Plan) Merge(other *Plan) {
+	pages := make(map[string]int)
+	for i, op := range p.Operations {
+		if op.combinable() {
+			pages[op.PageID] = i
+		}
+	}
+	for _, op := range other.Operations {
+		i, ok := pages[op.PageID]
+		if !ok || !op.combinable() {
+			p.Operations = append(p.Operations, op)
+			continue
+		}
+		p.Operations[i].combine(op)
+	}
+}
+
+// combinable reports whether the operation writes properties of an existing
+// page, so another one on the same page can be folded into it
+func (op Operation) combinable() bool {
+	return op.PageID != "" && (op.Kind == OperationUpdate || op.Kind == OperationTransition)
+}
+
+// combine folds the changes of another operation on the same page into op,
+// skipping properties op already writes. op becomes a transition when it
+// takes the other operation's status
+func (op *Operation) combine(other Operation) {
+	written := make(map[string]bool, len(op.Changes))
+	for _, change := range op.Changes {
+		written[change.Property] = true
+	}
+	for _, change := range other.Changes {
+		if written[change.Property] {
+			continue
+		}
+		op.Changes = append(op.Changes, change)
+		op.properties[change.Property] = other.properties[change.Property]
+		if other.Kind == OperationTransition && change.Property == "Status" {
+			op.Kind = OperationTransition
+			if op.Reason == "" {
+				op.Reason = other.Reason
+			}
+		}
+	}
+}
+
+// Summary counts the planned operations of each kind
+func (p *Plan) Summary() string {
+	counts := make(map[OperationKind]int)
+	for _, op := range p.Operations {
+		counts[op.Kind]++
+	}
+	return fmt.Sprintf("%d to create, %d to update, %d to transition, %d to archive",
+		counts[OperationCreate], counts[OperationUpdate], counts[OperationTransition], counts[OperationArchive])
+}
+
+// WriteTable writes the plan as a table with one row per changed property
+func (p *Plan) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tPAGE\tPROPERTY\tBEFORE\tAFTER")
+	for _, op := range p.Operations {
+		label := string(op.Kind)
+		if op.Reason != "" {
+			label = fmt.Sprintf("%s (%s)", op.Kind, op.Reason)
+		}
+		if len(op.Changes) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\n", label, op.Title)
+			continue
+		}
+		for i, change := range op.Changes {
+			kind, title := label, op.Title
+			if i > 0 { // only label the first row of each operation
+				kind, title = "", ""
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", kind, title, change.Property,
+				tableValue(change.Before), tableValue(change.After))
+		}
+	}
+	fmt.Fprintln(tw, p.Summary())
+	return tw.Flush()
+}
+
+// WriteJSON writes the plan as indented JSON
+func (p *Plan) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// ApplyPlan executes every operation in the plan in order, stopping at the
+// first one that fails
+func (nc *NotionClient) ApplyPlan(plan *Plan) error {
+	for _, op := range plan.Operations {
+		media := op.media
+		if media == nil {
+			media = Games
+		}
+		var err error
+		switch op.Kind {
+		case OperationCreate:
+			_, err = nc.createPage(media, op.properties)
+		case OperationUpdate, OperationTransition:
+			err = nc.updatePage(media, op.PageID, op.properties)
+		case OperationArchive:
+			err = nc.archivePage(op.PageID)
+		default:
+			err = fmt.Errorf("unknown operation %s", op.Kind)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s page %s: %s", op.Kind, op.Title, err.Error())
+		}
+	}
+	return nil
+}
+
+// PlanArchive plans archiving a game page
+func (nc *NotionClient) PlanArchive(existing GameProperties) *Operation {
+	return &Operation{Kind: OperationArchive, PageID: existing.PageID, Title: existing.Title(), media: Games}
+}
+
+// PlanTransition plans moving a game page to a new status, or returns nil when
+// it already has that status. Games moved to Finished without a Completed
+// Date get today's date
+func (nc *NotionClient) PlanTransition(existing GameProperties, status string) (*Operation, error) {
+	props := notionapi.Properties{
+		"Status": &notionapi.StatusProperty{Status: notionapi.Option{Name: status}},
+	}
+	if status == StatusFinished && (existing.CompletedDate == nil || existing.CompletedDate.Date == nil) {
+		props["Completed Date"] = nc.todayProperty()
+	}
+	return nc.planUpdate(OperationTransition, existing, props, nil)
+}
+
+// planUpdate plans writing the properties of an existing game page that
+// differ from props, or returns nil when none do. Columns the database
+// doesn't have and properties skip returns true for are left out
+func (nc *NotionClient) planUpdate(kind OperationKind, existing GameProperties, props notionapi.Properties, skip func(name string) bool) (*Operation, error) {
+	op, err := nc.planPageUpdate(kind, Games, existing.PageID, existing.properties, props, skip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan %s of game %s: %s", kind, existing.Title(), err.Error())
+	}
+	if op != nil {
+		op.Title = existing.Title()
+	}
+	return op, nil
+}
+
+// planPageUpdate plans writing the properties of a page of a kind of media
+// that differ from its existing ones, or returns nil when none do. Columns
+// the database doesn't have and properties skip returns true for are left out
+func (nc *NotionClient) planPageUpdate(kind OperationKind, media *MediaKind, pageID string, existing notionapi.Properties, props notionapi.Properties, skip func(name string) bool) (*Operation, error) {
+	props, err := nc.pruneProperties(media, props)
+	if err != nil {
+		return nil, err
+	}
+	props, changes := diffProperties(existing, props, skip, nc.clock)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return &Operation{Kind: kind, PageID: pageID, Changes: changes, media: media, properties: props}, nil
+}
+
+// planPageCreate plans creating a page of a kind of media. Columns the
+// database doesn't have are left out
+func (nc *NotionClient) planPageCreate(media *MediaKind, title string, props notionapi.Properties) (*Operation, error) {
+	props, err := nc.pruneProperties(media, props)
+	if err != nil {
+		return nil, err
+	}
+	props, changes := diffProperties(notionapi.Properties{}, props, nil, nc.clock)
+	return &Operation{Kind: OperationCreate, Title: title, Changes: changes, media: media, properties: props}, nil
+}
+
+// diffProperties returns the desired properties whose values differ from the
+// existing ones, along with the changes they make. Properties skip returns
+// true for are left out and dates are compared by the clock's days
+func diffProperties(existing notionapi.Properties, desired notionapi.Properties, skip func(name string) bool, c clock.Clock) (notionapi.Properties, []PropertyChange) {
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	props := notionapi.Properties{}
+	var changes []PropertyChange
+	for _, name := range names {
+		if skip != nil && skip(name) {
+			continue
+		}
+		before := propertyValue(existing[name], c)
+		after := propertyValue(desired[name], c)
+		if before == after {
+			continue
+		}
+		props[name] = desired[name]
+		changes = append(changes, PropertyChange{Property: name, Before: before, After: after})
+	}
+	return props, changes
+}
+
+// tableValue shortens long values so table rows stay on one line
+func tableValue(value string) string {
+	value = strings.ReplaceAll(displayValue(value), "\n", " ")
+	if runes := []rune(value); len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return value
+}
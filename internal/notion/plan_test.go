@@ -0,0 +1,92 @@
+package notion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+// testOperation builds an operation on a page that writes the given values
+func testOperation(kind OperationKind, pageID string, values map[string]string) Operation {
+	op := Operation{Kind: kind, PageID: pageID, Title: pageID, properties: notionapi.Properties{}}
+	for _, name := range []string{"Hours Played", "Last Played", "Status"} {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		op.Changes = append(op.Changes, PropertyChange{Property: name, After: value})
+		op.properties[name] = &notionapi.RichTextProperty{RichText: richText(value)}
+	}
+	return op
+}
+
+func TestPlanMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		plan  []Operation
+		other []Operation
+		want  []Operation
+	}{
+		{
+			name:  "different pages are appended",
+			plan:  []Operation{testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2"})},
+			other: []Operation{testOperation(OperationTransition, "b", map[string]string{"Status": StatusPlaying})},
+			want: []Operation{
+				testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2"}),
+				testOperation(OperationTransition, "b", map[string]string{"Status": StatusPlaying}),
+			},
+		},
+		{
+			name:  "same playtime is written once",
+			plan:  []Operation{testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2", "Last Played": "2025-10-16"})},
+			other: []Operation{testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2", "Last Played": "2025-10-16"})},
+			want:  []Operation{testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2", "Last Played": "2025-10-16"})},
+		},
+		{
+			name:  "status is folded into an update",
+			plan:  []Operation{testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2"})},
+			other: []Operation{testOperation(OperationTransition, "a", map[string]string{"Hours Played": "2", "Status": StatusPlaying})},
+			want:  []Operation{testOperation(OperationTransition, "a", map[string]string{"Hours Played": "2", "Status": StatusPlaying})},
+		},
+		{
+			name:  "planned status wins",
+			plan:  []Operation{testOperation(OperationTransition, "a", map[string]string{"Status": StatusFinished})},
+			other: []Operation{testOperation(OperationTransition, "a", map[string]string{"Hours Played": "2", "Status": StatusPlaying})},
+			want: []Operation{func() Operation {
+				op := testOperation(OperationTransition, "a", map[string]string{"Status": StatusFinished})
+				op.Changes = append(op.Changes, PropertyChange{Property: "Hours Played", After: "2"})
+				op.properties["Hours Played"] = &notionapi.RichTextProperty{RichText: richText("2")}
+				return op
+			}()},
+		},
+		{
+			name:  "creates aren't combined",
+			plan:  []Operation{testOperation(OperationCreate, "", map[string]string{"Status": StatusUpNext})},
+			other: []Operation{testOperation(OperationCreate, "", map[string]string{"Status": StatusPlaying})},
+			want: []Operation{
+				testOperation(OperationCreate, "", map[string]string{"Status": StatusUpNext}),
+				testOperation(OperationCreate, "", map[string]string{"Status": StatusPlaying}),
+			},
+		},
+		{
+			name:  "archives aren't combined",
+			plan:  []Operation{{Kind: OperationArchive, PageID: "a", Title: "a"}},
+			other: []Operation{testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2"})},
+			want: []Operation{
+				{Kind: OperationArchive, PageID: "a", Title: "a"},
+				testOperation(OperationUpdate, "a", map[string]string{"Hours Played": "2"}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &Plan{Operations: tt.plan}
+			plan.Merge(&Plan{Operations: tt.other})
+			if !reflect.DeepEqual(plan.Operations, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", plan.Operations, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package notion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// Repository reads and writes the pages of one kind of media in the database
+// for the client's environment, decoding each page it reads into P
+type Repository[P any] struct {
+	nc     *NotionClient
+	kind   *MediaKind
+	decode func(page notionapi.Page) P
+}
+
+// NewRepository creates a repository for a kind of media. decode converts a
+// page to the kind's properties type and is called on every page read
+func NewRepository[P any](nc *NotionClient, kind *MediaKind, decode func(page notionapi.Page) P) *Repository[P] {
+	return &Repository[P]{nc: nc, kind: kind, decode: decode}
+}
+
+// Games returns the repository of the Games DB
+func (nc *NotionClient) Games() *Repository[GameProperties] {
+	return NewRepository(nc, Games, newGameProperties)
+}
+
+// Anime returns the repository of the Anime DB
+func (nc *NotionClient) Anime() *Repository[AnimeProperties] {
+	return NewRepository(nc, Anime, newAnimeProperties)
+}
+
+// Movies returns the repository of the Movies DB
+func (nc *NotionClient) Movies() *Repository[MovieProperties] {
+	return NewRepository(nc, Movies, newMovieProperties)
+}
+
+// TV returns the repository of the TV DB
+func (nc *NotionClient) TV() *Repository[TVProperties] {
+	return NewRepository(nc, TV, newTVProperties)
+}
+
+// Kind returns the kind of media the repository holds
+func (r *Repository[P]) Kind() *MediaKind {
+	return r.kind
+}
+
+// DatabaseID returns the database the repository reads and writes
+func (r *Repository[P]) DatabaseID() string {
+	return r.nc.DatabaseID(r.kind)
+}
+
+// List retrieves every page in the database matching options, sorted by Name
+// unless options say otherwise. Sorts and filters, including nested and/or
+// filters, name fields and are renamed to the properties they're stored in.
+// An error is returned without reading any pages when the database is
+// missing required properties
+func (r *Repository[P]) List(options *notionapi.DatabaseQueryRequest) ([]P, error) {
+	err := r.nc.checkRequired(r.kind)
+	if err != nil {
+		return nil, err
+	}
+	names, err := r.nc.propertyNames(r.kind)
+	if err != nil {
+		return nil, err
+	}
+	databaseID := r.DatabaseID()
+	query := *setQueryOptions(options)
+	query.Sorts = toNotionSorts(query.Sorts, names)
+	query.Filter = toNotionFilter(query.Filter, names)
+	pages, err := r.nc.client.GetDatabasePages(databaseID, &query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s pages from database id %s: %s", r.kind.Name, databaseID, err.Error())
+	}
+
+	var decoded []P
+	for _, page := range pages {
+		page.Properties = fromNotionProperties(page.Properties, names)
+		decoded = append(decoded, r.decode(page))
+	}
+	return decoded, nil
+}
+
+// Get retrieves a single page, which must be in the database
+func (r *Repository[P]) Get(pageID string) (P, error) {
+	var decoded P
+	err := r.nc.checkRequired(r.kind)
+	if err != nil {
+		return decoded, err
+	}
+	page, err := r.nc.client.GetPageByID(r.nc.ctx, pageID)
+	if err != nil {
+		return decoded, fmt.Errorf("failed to get %s page id %s: %s", r.kind.Name, pageID, err.Error())
+	}
+	if !sameID(string(page.Parent.DatabaseID), r.DatabaseID()) {
+		return decoded, fmt.Errorf("page id %s is not in the %s database", pageID, r.kind.Plural)
+	}
+	names, err := r.nc.propertyNames(r.kind)
+	if err != nil {
+		return decoded, err
+	}
+	page.Properties = fromNotionProperties(page.Properties, names)
+	return r.decode(*page), nil
+}
+
+// Create adds a page to the database. Optional columns the database doesn't
+// have are left out rather than failing the request
+func (r *Repository[P]) Create(props notionapi.Properties) (P, error) {
+	var decoded P
+	page, err := r.nc.createPage(r.kind, props)
+	if err != nil {
+		return decoded, fmt.Errorf("failed to create %s page: %s", r.kind.Name, err.Error())
+	}
+	return r.decode(*page), nil
+}
+
+// Update writes properties to a page in the database. Optional columns the
+// database doesn't have are left out rather than failing the request
+func (r *Repository[P]) Update(pageID string, props notionapi.Properties) error {
+	return r.nc.updatePage(r.kind, pageID, props)
+}
+
+// Archive moves a page in the database to the trash
+func (r *Repository[P]) Archive(pageID string) error {
+	return r.nc.archivePage(pageID)
+}
+
+// sameID reports whether two Notion IDs are the same, whether or not they're
+// written with dashes
+func sameID(a string, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "-", ""), strings.ReplaceAll(b, "-", ""))
+}
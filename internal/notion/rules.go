@@ -0,0 +1,224 @@
+package notion
+
+import (
+	"fmt"
+	"kanbanchan/internal/clock"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+const day = 24 * time.Hour
+
+// Rule moves games that match every one of its conditions to a status
+type Rule struct {
+	Name string     `json:"name"`
+	To   string     `json:"to"`
+	When Conditions `json:"when"`
+}
+
+// Conditions are the checks a game must pass for a rule to apply. Unset
+// conditions always pass
+type Conditions struct {
+	// Status matches games currently in any of these statuses
+	Status []string `json:"status,omitempty"`
+	// Released matches games whose release day has arrived in the clock's
+	// time zone (true) or hasn't yet (false). Games due in a range of days
+	// count as released once the range has ended. Games without a release
+	// date never match
+	Released *bool `json:"released,omitempty"`
+	// PlayedWithinDays matches games last played at most this many days ago
+	PlayedWithinDays int `json:"playedWithinDays,omitempty"`
+	// NotPlayedForDays matches games last played more than this many days
+	// ago. Games that have never been played don't match
+	NotPlayedForDays int `json:"notPlayedForDays,omitempty"`
+	// MinHoursPlayed and MaxHoursPlayed bound the Hours Played column
+	MinHoursPlayed *float64 `json:"minHoursPlayed,omitempty"`
+	MaxHoursPlayed *float64 `json:"maxHoursPlayed,omitempty"`
+	// MinCompletionPercent matches games with at least this much of their
+	// achievements unlocked
+	MinCompletionPercent *float64 `json:"minCompletionPercent,omitempty"`
+	// Owned matches games in the Steam library (true) or not in it (false)
+	Owned *bool `json:"owned,omitempty"`
+	// Collections matches games in any of these collections, such as Finished
+	Collections []string `json:"collections,omitempty"`
+}
+
+// RuleInput is a game page along with the Steam facts rules can check that
+// aren't stored on the page
+type RuleInput struct {
+	Game GameProperties
+	// Owned is nil when ownership isn't known, which fails any Owned condition
+	Owned       *bool
+	Collections map[string]bool
+}
+
+// TransitionEngine picks the status a game should move to from an ordered
+// list of rules. The first matching rule that would change the game's status wins
+type TransitionEngine struct {
+	rules []Rule
+}
+
+// DefaultRules move released games out of Unreleased
+func DefaultRules() []Rule {
+	released := true
+	return []Rule{{
+		Name: "released",
+		To:   StatusUnowned,
+		When: Conditions{Status: []string{StatusUnreleased}, Released: &released},
+	}}
+}
+
+// NewTransitionEngine validates rules and returns an engine that evaluates
+// them in order. DefaultRules are used when there are none. Rules may only
+// name the statuses of the Games DB
+func NewTransitionEngine(rules []Rule) (*TransitionEngine, error) {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	rules = append([]Rule(nil), rules...)
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rules[i].Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.To == "" {
+			return nil, fmt.Errorf("transition %s has no status to move games to", rules[i].Name)
+		}
+		if !containsString(Games.Statuses, rule.To) {
+			return nil, fmt.Errorf("transition %s moves games to unknown status %s, expected one of %s",
+				rules[i].Name, rule.To, strings.Join(Games.Statuses, ", "))
+		}
+		for _, status := range rule.When.Status {
+			if !containsString(Games.Statuses, status) {
+				return nil, fmt.Errorf("transition %s matches unknown status %s, expected one of %s",
+					rules[i].Name, status, strings.Join(Games.Statuses, ", "))
+			}
+		}
+		if rule.When.PlayedWithinDays < 0 || rule.When.NotPlayedForDays < 0 {
+			return nil, fmt.Errorf("transition %s has a negative number of days", rules[i].Name)
+		}
+	}
+	return &TransitionEngine{rules: rules}, nil
+}
+
+// NeedsSteam reports whether any rule checks ownership or collections, which
+// have to come from Steam
+func (te *TransitionEngine) NeedsSteam() bool {
+	for _, rule := range te.rules {
+		if rule.When.Owned != nil || len(rule.When.Collections) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate returns the first rule that matches the game at the clock's current
+// time and would change its status, or nil when none do
+func (te *TransitionEngine) Evaluate(input RuleInput, c clock.Clock) *Rule {
+	status := ""
+	if input.Game.Status != nil {
+		status = input.Game.Status.Status.Name
+	}
+	for i, rule := range te.rules {
+		if rule.To != status && rule.When.match(input, status, c) {
+			return &te.rules[i]
+		}
+	}
+	return nil
+}
+
+func (c Conditions) match(input RuleInput, status string, clk clock.Clock) bool {
+	game := input.Game
+	if len(c.Status) > 0 && !containsString(c.Status, status) {
+		return false
+	}
+
+	if c.Released != nil {
+		// Dates written by kanbanchan are midnight in the user's zone and
+		// date-only Notion dates midnight UTC, so both read as the day they
+		// name. Only a date with a time is placed in the clock's zone
+		releaseDate, ok := dateEnd(game.ReleaseDate)
+		if !ok || clock.OnOrBeforeToday(releaseDate, clk) != *c.Released {
+			return false
+		}
+	}
+
+	if c.PlayedWithinDays > 0 || c.NotPlayedForDays > 0 {
+		lastPlayed, ok := dateStart(game.LastPlayed)
+		if !ok {
+			return false
+		}
+		since := clk.Now().Sub(lastPlayed)
+		if c.PlayedWithinDays > 0 && since > time.Duration(c.PlayedWithinDays)*day {
+			return false
+		}
+		if c.NotPlayedForDays > 0 && since <= time.Duration(c.NotPlayedForDays)*day {
+			return false
+		}
+	}
+
+	if c.MinHoursPlayed != nil || c.MaxHoursPlayed != nil {
+		hours := 0.0
+		if game.HoursPlayed != nil {
+			hours = game.HoursPlayed.Number
+		}
+		if c.MinHoursPlayed != nil && hours < *c.MinHoursPlayed {
+			return false
+		}
+		if c.MaxHoursPlayed != nil && hours > *c.MaxHoursPlayed {
+			return false
+		}
+	}
+
+	if c.MinCompletionPercent != nil &&
+		(game.CompletionPercent == nil || game.CompletionPercent.Number < *c.MinCompletionPercent) {
+		return false
+	}
+
+	if c.Owned != nil && (input.Owned == nil || *input.Owned != *c.Owned) {
+		return false
+	}
+
+	if len(c.Collections) > 0 {
+		inAny := false
+		for _, collection := range c.Collections {
+			if input.Collections[collection] {
+				inAny = true
+				break
+			}
+		}
+		if !inAny {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dateStart returns the start of a date property, if it has one
+func dateStart(prop *notionapi.DateProperty) (time.Time, bool) {
+	if prop == nil || prop.Date == nil || prop.Date.Start == nil {
+		return time.Time{}, false
+	}
+	start := time.Time(*prop.Date.Start)
+	return start, !start.IsZero()
+}
+
+// dateEnd returns the end of a date property's range, or its start when it
+// isn't a range
+func dateEnd(prop *notionapi.DateProperty) (time.Time, bool) {
+	if prop != nil && prop.Date != nil && prop.Date.End != nil && !time.Time(*prop.Date.End).IsZero() {
+		return time.Time(*prop.Date.End), true
+	}
+	return dateStart(prop)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
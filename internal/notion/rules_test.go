@@ -0,0 +1,388 @@
+package notion
+
+import (
+	"kanbanchan/internal/clock"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// dateProperty returns a date property from start to end, or a single day
+// when end is zero
+func dateProperty(start time.Time, end time.Time) *notionapi.DateProperty {
+	startDate := notionapi.Date(start)
+	date := &notionapi.DateObject{Start: &startDate}
+	if !end.IsZero() {
+		endDate := notionapi.Date(end)
+		date.End = &endDate
+	}
+	return &notionapi.DateProperty{Date: date}
+}
+
+// dateOnly returns a day as Notion decodes a date without a time
+func dateOnly(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone data isn't available: %s", err.Error())
+	}
+	return location
+}
+
+func TestConditionsMatch(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	losAngeles := mustLoadLocation(t, "America/Los_Angeles")
+	yes, no := true, false
+	hours := func(h float64) *float64 { return &h }
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		conditions Conditions
+		game       GameProperties
+		owned      *bool
+		now        time.Time
+		want       bool
+	}{
+		// Release days are compared in the clock's zone
+		{
+			name:       "released on its day in a zone ahead of UTC",
+			conditions: Conditions{Released: &yes},
+			game:       GameProperties{ReleaseDate: dateProperty(dateOnly(2026, 10, 16), time.Time{})},
+			now:        time.Date(2026, 10, 16, 0, 30, 0, 0, tokyo), // still the 15th in UTC
+			want:       true,
+		},
+		{
+			name:       "not released the day before in a zone ahead of UTC",
+			conditions: Conditions{Released: &no},
+			game:       GameProperties{ReleaseDate: dateProperty(dateOnly(2026, 10, 16), time.Time{})},
+			now:        time.Date(2026, 10, 15, 23, 59, 0, 0, tokyo),
+			want:       true,
+		},
+		{
+			name:       "not released the evening before in a zone behind UTC",
+			conditions: Conditions{Released: &yes},
+			game:       GameProperties{ReleaseDate: dateProperty(dateOnly(2026, 10, 16), time.Time{})},
+			now:        time.Date(2026, 10, 15, 20, 0, 0, 0, losAngeles), // already the 16th in UTC
+			want:       false,
+		},
+		{
+			name:       "released on its day in a zone behind UTC",
+			conditions: Conditions{Released: &yes},
+			game:       GameProperties{ReleaseDate: dateProperty(dateOnly(2026, 10, 16), time.Time{})},
+			now:        time.Date(2026, 10, 16, 0, 1, 0, 0, losAngeles),
+			want:       true,
+		},
+		{
+			name:       "release time falls on the previous day in a zone behind UTC",
+			conditions: Conditions{Released: &yes},
+			game:       GameProperties{ReleaseDate: dateProperty(time.Date(2026, 10, 16, 5, 0, 0, 0, time.UTC), time.Time{})},
+			now:        time.Date(2026, 10, 15, 23, 0, 0, 0, losAngeles),
+			want:       true,
+		},
+		{
+			name:       "midnight in another zone names its own day",
+			conditions: Conditions{Released: &yes},
+			game:       GameProperties{ReleaseDate: dateProperty(time.Date(2026, 10, 16, 0, 0, 0, 0, tokyo), time.Time{})},
+			now:        time.Date(2026, 10, 15, 23, 0, 0, 0, losAngeles),
+			want:       false,
+		},
+		{
+			name:       "release window isn't released before it ends",
+			conditions: Conditions{Released: &no},
+			game:       GameProperties{ReleaseDate: dateProperty(dateOnly(2026, 10, 1), dateOnly(2026, 12, 31))},
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "release window is released on its last day",
+			conditions: Conditions{Released: &yes},
+			game:       GameProperties{ReleaseDate: dateProperty(dateOnly(2026, 10, 1), dateOnly(2026, 12, 31))},
+			now:        time.Date(2026, 12, 31, 9, 0, 0, 0, losAngeles),
+			want:       true,
+		},
+		{
+			name:       "no release date is never released",
+			conditions: Conditions{Released: &yes},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "no release date is never unreleased",
+			conditions: Conditions{Released: &no},
+			game:       GameProperties{ReleaseDate: &notionapi.DateProperty{}},
+			now:        now,
+			want:       false,
+		},
+
+		// Play activity
+		{
+			name:       "played within days",
+			conditions: Conditions{PlayedWithinDays: 7},
+			game:       GameProperties{LastPlayed: dateProperty(now.Add(-7*day), time.Time{})},
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "not played within days",
+			conditions: Conditions{PlayedWithinDays: 7},
+			game:       GameProperties{LastPlayed: dateProperty(now.Add(-7*day-time.Minute), time.Time{})},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "never played isn't played within days",
+			conditions: Conditions{PlayedWithinDays: 7},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "not played for days",
+			conditions: Conditions{NotPlayedForDays: 30},
+			game:       GameProperties{LastPlayed: dateProperty(now.Add(-30*day-time.Minute), time.Time{})},
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "played exactly the number of days ago",
+			conditions: Conditions{NotPlayedForDays: 30},
+			game:       GameProperties{LastPlayed: dateProperty(now.Add(-30*day), time.Time{})},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "never played doesn't count as not played for days",
+			conditions: Conditions{NotPlayedForDays: 30},
+			now:        now,
+			want:       false,
+		},
+
+		// Hours and completion
+		{
+			name:       "within hour bounds",
+			conditions: Conditions{MinHoursPlayed: hours(2), MaxHoursPlayed: hours(10)},
+			game:       GameProperties{HoursPlayed: &notionapi.NumberProperty{Number: 10}},
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "under the minimum hours",
+			conditions: Conditions{MinHoursPlayed: hours(2)},
+			game:       GameProperties{HoursPlayed: &notionapi.NumberProperty{Number: 1.9}},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "over the maximum hours",
+			conditions: Conditions{MaxHoursPlayed: hours(10)},
+			game:       GameProperties{HoursPlayed: &notionapi.NumberProperty{Number: 10.1}},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "no hours played counts as zero",
+			conditions: Conditions{MaxHoursPlayed: hours(0)},
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "completion at the minimum",
+			conditions: Conditions{MinCompletionPercent: hours(100)},
+			game:       GameProperties{CompletionPercent: &notionapi.NumberProperty{Number: 100}},
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "completion under the minimum",
+			conditions: Conditions{MinCompletionPercent: hours(100)},
+			game:       GameProperties{CompletionPercent: &notionapi.NumberProperty{Number: 99.9}},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "no completion never matches a minimum",
+			conditions: Conditions{MinCompletionPercent: hours(0)},
+			now:        now,
+			want:       false,
+		},
+
+		// Ownership
+		{
+			name:       "owned",
+			conditions: Conditions{Owned: &yes},
+			owned:      &yes,
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "not owned",
+			conditions: Conditions{Owned: &no},
+			owned:      &no,
+			now:        now,
+			want:       true,
+		},
+		{
+			name:       "owned doesn't match not owned",
+			conditions: Conditions{Owned: &no},
+			owned:      &yes,
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "unknown ownership fails owned",
+			conditions: Conditions{Owned: &yes},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "unknown ownership fails not owned",
+			conditions: Conditions{Owned: &no},
+			now:        now,
+			want:       false,
+		},
+		{
+			name:       "ownership unchecked",
+			conditions: Conditions{},
+			now:        now,
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := RuleInput{Game: tt.game, Owned: tt.owned}
+			got := tt.conditions.match(input, "", clock.Fixed(tt.now))
+			if got != tt.want {
+				t.Errorf("match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransitionEngineEvaluate(t *testing.T) {
+	yes := true
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	released := GameProperties{
+		Status:      &notionapi.StatusProperty{Status: notionapi.Option{Name: StatusUnreleased}},
+		ReleaseDate: dateProperty(dateOnly(2026, 10, 16), time.Time{}),
+	}
+	owned := GameProperties{
+		Status:      &notionapi.StatusProperty{Status: notionapi.Option{Name: StatusUnowned}},
+		ReleaseDate: dateProperty(dateOnly(2020, 1, 1), time.Time{}),
+	}
+
+	tests := []struct {
+		name        string
+		rules       []Rule
+		game        GameProperties
+		collections map[string]bool
+		want        string // the name of the rule that applies, if any
+	}{
+		{
+			name: "default rule moves released games",
+			game: released,
+			want: "released",
+		},
+		{
+			name: "default rule leaves other statuses",
+			game: owned,
+		},
+		{
+			name: "first matching rule wins",
+			rules: []Rule{
+				{Name: "up next", To: StatusUpNext, When: Conditions{Released: &yes}},
+				{Name: "unowned", To: StatusUnowned, When: Conditions{Released: &yes}},
+			},
+			game: released,
+			want: "up next",
+		},
+		{
+			name: "rules that don't match are passed over",
+			rules: []Rule{
+				{Name: "finished", To: StatusFinished, When: Conditions{Status: []string{StatusPlaying}}},
+				{Name: "unowned", To: StatusUnowned, When: Conditions{Released: &yes}},
+			},
+			game: released,
+			want: "unowned",
+		},
+		{
+			name: "rules that wouldn't change the status are passed over",
+			rules: []Rule{
+				{Name: "unowned", To: StatusUnowned},
+				{Name: "up next", To: StatusUpNext},
+			},
+			game: owned,
+			want: "up next",
+		},
+		{
+			name: "every condition of a rule must match",
+			rules: []Rule{
+				{Name: "playing", To: StatusPlaying, When: Conditions{Status: []string{StatusUnowned}, Collections: []string{"Playing"}}},
+			},
+			game:        owned,
+			collections: map[string]bool{"Up Next": true},
+		},
+		{
+			name: "any collection matches",
+			rules: []Rule{
+				{Name: "playing", To: StatusPlaying, When: Conditions{Collections: []string{"Up Next", "Playing"}}},
+			},
+			game:        owned,
+			collections: map[string]bool{"Playing": true},
+			want:        "playing",
+		},
+		{
+			name: "unnamed rules are numbered",
+			rules: []Rule{
+				{To: StatusFinished, When: Conditions{Status: []string{StatusPlaying}}},
+				{To: StatusUpNext},
+			},
+			game: owned,
+			want: "rule 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewTransitionEngine(tt.rules)
+			if err != nil {
+				t.Fatalf("NewTransitionEngine() error = %s", err.Error())
+			}
+			rule := engine.Evaluate(RuleInput{Game: tt.game, Collections: tt.collections}, clock.Fixed(now))
+			got := ""
+			if rule != nil {
+				got = rule.Name
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTransitionEngineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{name: "no status", rule: Rule{Name: "empty"}},
+		{name: "unknown status to move to", rule: Rule{To: "Up next"}},
+		{name: "unknown status to match", rule: Rule{To: StatusUpNext, When: Conditions{Status: []string{"Wishlist"}}}},
+		{name: "negative days played within", rule: Rule{To: StatusUpNext, When: Conditions{PlayedWithinDays: -1}}},
+		{name: "negative days not played for", rule: Rule{To: StatusUpNext, When: Conditions{NotPlayedForDays: -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTransitionEngine([]Rule{tt.rule})
+			if err == nil {
+				t.Errorf("NewTransitionEngine() should fail")
+			}
+		})
+	}
+}
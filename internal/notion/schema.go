@@ -0,0 +1,275 @@
+package notion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// Ways a database can differ from the schema kanbanchan expects
+const (
+	ProblemMissing       = "missing"
+	ProblemWrongType     = "wrong type"
+	ProblemMissingOption = "missing option"
+)
+
+// SchemaProblem is a way a database differs from what kanbanchan expects of
+// its kind of media
+type SchemaProblem struct {
+	Field    string `json:"field"`    // the field kanbanchan stores in the property
+	Property string `json:"property"` // the property's name in Notion
+	Problem  string `json:"problem"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Required bool   `json:"required"` // pages can't be read until it's fixed
+	Fixable  bool   `json:"fixable"`  // ProvisionSchema can fix it through the API
+}
+
+// String describes a problem in a sentence
+func (sp SchemaProblem) String() string {
+	switch sp.Problem {
+	case ProblemMissing:
+		return fmt.Sprintf("%s is missing, expected a %s property", sp.Property, sp.Expected)
+	case ProblemWrongType:
+		return fmt.Sprintf("%s is a %s property, expected %s", sp.Property, sp.Actual, sp.Expected)
+	case ProblemMissingOption:
+		return fmt.Sprintf("%s is missing the %s option", sp.Property, sp.Expected)
+	}
+	return fmt.Sprintf("%s: %s", sp.Property, sp.Problem)
+}
+
+// PropertyType returns the type of a property config. notionapi reports no
+// type for status properties
+func PropertyType(config notionapi.PropertyConfig) notionapi.PropertyConfigType {
+	if _, ok := config.(*notionapi.StatusPropertyConfig); ok {
+		return notionapi.PropertyConfigStatus
+	}
+	return config.GetType()
+}
+
+// VerifySchema compares the database of a kind of media to the properties,
+// types and options kanbanchan expects of it, returning every difference
+func (nc *NotionClient) VerifySchema(kind *MediaKind) ([]SchemaProblem, error) {
+	problems, _, err := nc.verifySchema(kind)
+	return problems, err
+}
+
+// verifySchema returns every way the database of a kind of media differs
+// from what kanbanchan expects, along with the database as it was fetched
+func (nc *NotionClient) verifySchema(kind *MediaKind) ([]SchemaProblem, *notionapi.Database, error) {
+	databaseID := nc.DatabaseID(kind)
+	db, err := nc.GetDatabase(databaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err := nc.propertyNames(kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var statusOptions map[string][]string
+	var problems []SchemaProblem
+	for _, spec := range kind.Properties {
+		name := names[spec.Name]
+		config, ok := db.Properties[name]
+		if !ok {
+			problems = append(problems, SchemaProblem{
+				Field:    spec.Name,
+				Property: name,
+				Problem:  ProblemMissing,
+				Expected: string(spec.Type),
+				Required: spec.Required,
+				// Notion only lets a database have one title, status
+				// properties can't be created through the API, and a field
+				// mapped to a property ID can't be created under that ID
+				Fixable: spec.Type != notionapi.PropertyConfigTypeTitle && spec.Type != notionapi.PropertyConfigStatus &&
+					!nc.mappedField(kind, spec.Name),
+			})
+			continue
+		}
+		actual := PropertyType(config)
+		if actual != spec.Type {
+			problems = append(problems, SchemaProblem{
+				Field:    spec.Name,
+				Property: name,
+				Problem:  ProblemWrongType,
+				Expected: string(spec.Type),
+				Actual:   string(actual),
+				Required: spec.Required,
+			})
+			continue
+		}
+
+		expected := kind.options(spec)
+		if len(expected) == 0 {
+			continue
+		}
+		var existing []string
+		if spec.Type == notionapi.PropertyConfigStatus {
+			if statusOptions == nil {
+				statusOptions, err = nc.client.GetStatusOptions(databaseID)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			existing = statusOptions[name]
+		} else {
+			for _, option := range selectConfigOptions(config) {
+				existing = append(existing, option.Name)
+			}
+		}
+		for _, option := range expected {
+			if !containsString(existing, option) {
+				problems = append(problems, SchemaProblem{
+					Field:    spec.Name,
+					Property: name,
+					Problem:  ProblemMissingOption,
+					Expected: option,
+					Fixable:  spec.Type != notionapi.PropertyConfigStatus,
+				})
+			}
+		}
+	}
+	return problems, db, nil
+}
+
+// ProvisionSchema adds the properties and select options the database of a
+// kind of media is missing, then verifies it again and returns the problems
+// left, like status options, which Notion only lets you add in the app
+func (nc *NotionClient) ProvisionSchema(kind *MediaKind) ([]SchemaProblem, error) {
+	problems, db, err := nc.verifySchema(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := notionapi.PropertyConfigs{}
+	for _, problem := range problems {
+		if !problem.Fixable {
+			continue
+		}
+		spec, _ := kind.Property(problem.Field)
+		switch problem.Problem {
+		case ProblemMissing:
+			configs[problem.Property] = newPropertyConfig(spec.Type, optionsNamed(kind.options(spec)))
+		case ProblemMissingOption:
+			config, ok := configs[problem.Property]
+			if !ok {
+				config = newPropertyConfig(spec.Type, selectConfigOptions(db.Properties[problem.Property]))
+				configs[problem.Property] = config
+			}
+			addSelectOption(config, problem.Expected)
+		}
+	}
+	if len(configs) == 0 {
+		return problems, nil
+	}
+
+	databaseID := nc.DatabaseID(kind)
+	_, err = nc.client.UpdateDatabase(databaseID, &notionapi.DatabaseUpdateRequest{Properties: configs})
+	if err != nil {
+		return nil, err
+	}
+	nc.schemaMu.Lock()
+	delete(nc.schemas, databaseID)
+	delete(nc.propertyIDs, databaseID)
+	nc.schemaMu.Unlock()
+	return nc.VerifySchema(kind)
+}
+
+// checkRequired returns an error describing the required properties of a
+// kind's database that are missing or have the wrong type, so pages aren't
+// read from a database kanbanchan can't make sense of
+func (nc *NotionClient) checkRequired(kind *MediaKind) error {
+	schema, err := nc.databaseProperties(nc.DatabaseID(kind))
+	if err != nil {
+		return err
+	}
+	names, err := nc.propertyNames(kind)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, spec := range kind.Properties {
+		if !spec.Required {
+			continue
+		}
+		name := names[spec.Name]
+		config, ok := schema[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is missing", name))
+		} else if actual := PropertyType(config); actual != spec.Type {
+			problems = append(problems, fmt.Sprintf("%s is %s instead of %s", name, actual, spec.Type))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the %s database doesn't have the expected properties (%s), run db verify %s for details",
+			kind.Plural, strings.Join(problems, ", "), kind.Plural)
+	}
+	return nil
+}
+
+// options returns the options a property is expected to have. A Status
+// property's options are the kind's statuses
+func (mk *MediaKind) options(spec PropertySpec) []string {
+	if spec.Type == notionapi.PropertyConfigStatus {
+		return mk.Statuses
+	}
+	return spec.Options
+}
+
+// newPropertyConfig returns the config of a new property of a type, with
+// options when it's a select or multi-select
+func newPropertyConfig(propertyType notionapi.PropertyConfigType, options []notionapi.Option) notionapi.PropertyConfig {
+	switch propertyType {
+	case notionapi.PropertyConfigTypeRichText:
+		return &notionapi.RichTextPropertyConfig{Type: propertyType}
+	case notionapi.PropertyConfigTypeNumber:
+		return &notionapi.NumberPropertyConfig{Type: propertyType, Number: notionapi.NumberFormat{Format: notionapi.FormatNumber}}
+	case notionapi.PropertyConfigTypeSelect:
+		return &notionapi.SelectPropertyConfig{Type: propertyType, Select: notionapi.Select{Options: options}}
+	case notionapi.PropertyConfigTypeMultiSelect:
+		return &notionapi.MultiSelectPropertyConfig{Type: propertyType, MultiSelect: notionapi.Select{Options: options}}
+	case notionapi.PropertyConfigTypeDate:
+		return &notionapi.DatePropertyConfig{Type: propertyType}
+	case notionapi.PropertyConfigTypeFiles:
+		return &notionapi.FilesPropertyConfig{Type: propertyType}
+	case notionapi.PropertyConfigTypeCheckbox:
+		return &notionapi.CheckboxPropertyConfig{Type: propertyType}
+	case notionapi.PropertyConfigTypeURL:
+		return &notionapi.URLPropertyConfig{Type: propertyType}
+	}
+	return nil
+}
+
+// selectConfigOptions returns the options of a select or multi-select
+// property config, or none for any other type
+func selectConfigOptions(config notionapi.PropertyConfig) []notionapi.Option {
+	switch config := config.(type) {
+	case *notionapi.SelectPropertyConfig:
+		return config.Select.Options
+	case *notionapi.MultiSelectPropertyConfig:
+		return config.MultiSelect.Options
+	}
+	return nil
+}
+
+// addSelectOption appends an option to a select or multi-select property config
+func addSelectOption(config notionapi.PropertyConfig, name string) {
+	switch config := config.(type) {
+	case *notionapi.SelectPropertyConfig:
+		config.Select.Options = append(config.Select.Options, notionapi.Option{Name: name})
+	case *notionapi.MultiSelectPropertyConfig:
+		config.MultiSelect.Options = append(config.MultiSelect.Options, notionapi.Option{Name: name})
+	}
+}
+
+// optionsNamed converts option names to options
+func optionsNamed(names []string) []notionapi.Option {
+	options := []notionapi.Option{}
+	for _, name := range names {
+		options = append(options, notionapi.Option{Name: name})
+	}
+	return options
+}
@@ -0,0 +1,262 @@
+package notion
+
+import (
+	"fmt"
+	"kanbanchan/internal/clock"
+	"kanbanchan/internal/steam"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// defaultUserOwnedProperties are only written when a page is created, so
+// edits made in Notion are never overwritten by a sync
+var defaultUserOwnedProperties = []string{"Name", "Status", "Platform", "Rating", "Notes", "Completed Date"}
+
+// PropertyChange is a single property written to a page by a sync
+type PropertyChange struct {
+	Property string `json:"property"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+}
+
+// WithUserOwnedProperties adds properties that syncs must never overwrite to
+// the defaults of Name, Status, Platform, Rating, Notes and Completed Date
+func WithUserOwnedProperties(names ...string) ClientOption {
+	return func(nc *NotionClient) {
+		for _, name := range names {
+			nc.settings.userOwned[name] = true
+		}
+	}
+}
+
+// PlanAddGame plans adding a game to the Games DB. Optional columns the
+// database doesn't have are left out rather than failing the request
+func (nc *NotionClient) PlanAddGame(game steam.SteamGame) (*Operation, error) {
+	properties := nc.steamProperties(game)
+	status := nc.determineGameStatus(game)
+
+	properties["Status"] = &notionapi.StatusProperty{
+		Status: notionapi.Option{
+			Name: status,
+		},
+	}
+	properties["Platform"] = &notionapi.MultiSelectProperty{
+		MultiSelect: []notionapi.Option{{Name: "Steam"}, {Name: "kanbanchan"}},
+	}
+	if status == StatusFinished && !game.Collections[steam.CollectionFinished] { // auto-finished
+		properties["Completed Date"] = nc.todayProperty()
+	}
+
+	op, err := nc.planPageCreate(Games, game.Name, properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add game %s: %s", game.Name, err.Error())
+	}
+	return op, nil
+}
+
+// PlanGameSync compares the Steam-tracked properties of an existing game page
+// to the game and plans updating only the properties that differ. User-owned
+// properties are left alone, except for Status and Completed Date when
+// auto-finish is enabled and the game has every achievement unlocked. Nil is
+// returned when the page is already up to date
+func (nc *NotionClient) PlanGameSync(existing GameProperties, game steam.SteamGame) (*Operation, error) {
+	op, err := nc.planUpdate(OperationUpdate, existing, nc.steamProperties(game), func(name string) bool {
+		return nc.settings.userOwned[name]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !nc.settings.autoFinish || !game.Achievements.Complete() ||
+		(existing.Status != nil && existing.Status.Status.Name == StatusFinished) {
+		return op, nil
+	}
+	finished := notionapi.Properties{
+		"Status": &notionapi.StatusProperty{
+			Status: notionapi.Option{Name: StatusFinished},
+		},
+	}
+	if existing.CompletedDate == nil || existing.CompletedDate.Date == nil {
+		finished["Completed Date"] = nc.todayProperty()
+	}
+	transition, err := nc.planUpdate(OperationTransition, existing, finished, nil)
+	if err != nil || transition == nil {
+		return op, err
+	}
+	if op != nil {
+		transition.Changes = append(op.Changes, transition.Changes...)
+		for name, prop := range op.properties {
+			transition.properties[name] = prop
+		}
+	}
+	return transition, nil
+}
+
+// steamProperties returns every property kanbanchan keeps in sync with Steam
+// for a game. Optional properties are only included when Steam has a value
+func (nc *NotionClient) steamProperties(game steam.SteamGame) notionapi.Properties {
+	properties := notionapi.Properties{}
+
+	properties["Name"] = &notionapi.TitleProperty{
+		Title: []notionapi.RichText{{
+			Text: &notionapi.Text{
+				Content: game.Name,
+			},
+			PlainText: game.Name,
+		}},
+	}
+	properties["Tags"] = &notionapi.MultiSelectProperty{
+		MultiSelect: selectOptions(game.Genres),
+	}
+	properties["Official Store Page"] = &notionapi.URLProperty{URL: steamStoreURL(game.ID)}
+	properties["Steam App ID"] = &notionapi.RichTextProperty{RichText: richText(game.ID)}
+	properties["Cover Art"] = &notionapi.FilesProperty{
+		Files: []notionapi.File{{
+			Name: game.HeaderImage,
+			Type: notionapi.FileTypeExternal,
+			External: &notionapi.FileObject{
+				URL: game.HeaderImage,
+			},
+		}},
+	}
+	properties["Release Date"] = nc.releaseDateProperty(game.ReleaseDate)
+	properties["Release Window"] = &notionapi.RichTextProperty{RichText: richText(game.ReleaseDate.Window())}
+	properties["Developer"] = &notionapi.MultiSelectProperty{
+		MultiSelect: selectOptions(game.Developers),
+	}
+	properties["Publisher"] = &notionapi.MultiSelectProperty{
+		MultiSelect: selectOptions(game.Publishers),
+	}
+	properties["Features"] = &notionapi.MultiSelectProperty{
+		MultiSelect: selectOptions(game.Categories),
+	}
+	properties["OS"] = &notionapi.MultiSelectProperty{
+		MultiSelect: selectOptions(game.Platforms),
+	}
+	properties["Description"] = &notionapi.RichTextProperty{
+		RichText: richText(game.ShortDescription),
+	}
+	if game.MetacriticScore > 0 {
+		properties["Metacritic"] = &notionapi.NumberProperty{Number: float64(game.MetacriticScore)}
+	}
+	if game.Price != "" {
+		properties["Price"] = &notionapi.RichTextProperty{RichText: richText(game.Price)}
+	} else if game.IsFree {
+		properties["Price"] = &notionapi.RichTextProperty{RichText: richText("Free")}
+	}
+
+	for name, prop := range achievementProperties(game) {
+		properties[name] = prop
+	}
+	for name, prop := range installProperties(game) {
+		properties[name] = prop
+	}
+	if game.Owned {
+		for name, prop := range playtimeProperties(game.Playtime, game.LastPlayed) {
+			properties[name] = prop
+		}
+	}
+	return properties
+}
+
+// releaseDateProperty returns the day a game releases, or the range of days
+// it's due in when the store only gives a month, quarter, season or year.
+// Days are written as midnight in the user's time zone so Notion shows the
+// same days the store does. Unknown dates are left empty
+func (nc *NotionClient) releaseDateProperty(releaseDate steam.ReleaseDate) *notionapi.DateProperty {
+	if !releaseDate.Known() {
+		return &notionapi.DateProperty{}
+	}
+	start := notionapi.Date(clock.Midnight(releaseDate.Start, nc.clock))
+	date := &notionapi.DateObject{Start: &start}
+	if !releaseDate.Exact() {
+		end := notionapi.Date(clock.Midnight(releaseDate.End, nc.clock))
+		date.End = &end
+	}
+	return &notionapi.DateProperty{Date: date}
+}
+
+// propertyValue renders a property's value as text so values read from Notion
+// can be compared with the values a sync would write. Dates are rendered as
+// the days they fall on in the clock's zone
+func propertyValue(prop notionapi.Property, c clock.Clock) string {
+	switch p := prop.(type) {
+	case *notionapi.TitleProperty:
+		return plainText(p.Title)
+	case *notionapi.RichTextProperty:
+		return plainText(p.RichText)
+	case *notionapi.URLProperty:
+		return p.URL
+	case *notionapi.NumberProperty:
+		return strconv.FormatFloat(p.Number, 'f', -1, 64)
+	case *notionapi.CheckboxProperty:
+		return strconv.FormatBool(p.Checkbox)
+	case *notionapi.StatusProperty:
+		return p.Status.Name
+	case *notionapi.SelectProperty:
+		return p.Select.Name
+	case *notionapi.MultiSelectProperty:
+		var names []string
+		for _, option := range p.MultiSelect {
+			names = append(names, option.Name)
+		}
+		sort.Strings(names)
+		return strings.Join(names, ", ")
+	case *notionapi.DateProperty:
+		if p.Date == nil || p.Date.Start == nil {
+			return ""
+		}
+		value := dateValue(time.Time(*p.Date.Start), c)
+		if p.Date.End != nil {
+			value += " to " + dateValue(time.Time(*p.Date.End), c)
+		}
+		return value
+	case *notionapi.FilesProperty:
+		var urls []string
+		for _, file := range p.Files {
+			if file.External != nil {
+				urls = append(urls, file.External.URL)
+			} else if file.File != nil {
+				urls = append(urls, file.File.URL)
+			}
+		}
+		return strings.Join(urls, ", ")
+	}
+	return ""
+}
+
+// dateValue formats a date at minute precision. Like clock.Day, midnight in
+// its own zone, such as a date-only value, is just the day it names, so it
+// reads the same as that day written with clock.Midnight. Other times are
+// shown in the clock's zone, without the time when it's midnight there
+func dateValue(t time.Time, c clock.Clock) string {
+	if t.IsZero() {
+		return ""
+	}
+	hour, min, sec := t.Clock()
+	midnight := hour == 0 && min == 0 && sec == 0 && t.Nanosecond() == 0 // in its own zone
+	if midnight || t.Equal(clock.Midnight(t, c)) {
+		return clock.Day(t, c).Format("2006-01-02")
+	}
+	return t.In(c.Location()).Format("2006-01-02 15:04")
+}
+
+func plainText(richText []notionapi.RichText) string {
+	var text strings.Builder
+	for _, rt := range richText {
+		text.WriteString(rt.PlainText)
+	}
+	return text.String()
+}
+
+// displayValue marks empty values so they're visible in output
+func displayValue(value string) string {
+	if value == "" {
+		return "<empty>"
+	}
+	return value
+}
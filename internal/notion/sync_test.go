@@ -0,0 +1,119 @@
+package notion
+
+import (
+	"kanbanchan/internal/clock"
+	"kanbanchan/internal/steam"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDateValue(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+	c := clock.Fixed(time.Date(2025, 10, 16, 9, 0, 0, 0, newYork))
+
+	tests := []struct {
+		name string
+		date time.Time
+		want string
+	}{
+		{name: "date-only value", date: dateOnly(2025, 10, 16), want: "2025-10-16"},
+		{name: "midnight in the clock's zone", date: clock.Midnight(dateOnly(2025, 10, 16), c), want: "2025-10-16"},
+		{name: "midnight in the clock's zone read back as UTC", date: clock.Midnight(dateOnly(2025, 10, 16), c).UTC(), want: "2025-10-16"},
+		{name: "midnight in a zone with a half hour offset", date: time.Date(2025, 10, 16, 0, 0, 0, 0, kolkata), want: "2025-10-16"},
+		{name: "time in the clock's zone", date: time.Date(2025, 10, 16, 18, 30, 0, 0, time.UTC), want: "2025-10-16 14:30"},
+		{name: "time on the previous day in the clock's zone", date: time.Date(2025, 10, 16, 2, 0, 0, 0, time.UTC), want: "2025-10-15 22:00"},
+		{name: "zero", date: time.Time{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dateValue(tt.date, c); got != tt.want {
+				t.Errorf("dateValue(%s) = %q, want %q", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffPropertiesDates(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	c := clock.Fixed(time.Date(2025, 10, 16, 9, 0, 0, 0, newYork))
+	nc := &NotionClient{clock: c}
+
+	// Dates read back from Notion without a time must match the same days
+	// written by a sync, or every plan would rewrite them
+	existing := notionapi.Properties{
+		"Release Date":   dateProperty(dateOnly(2025, 10, 1), dateOnly(2025, 12, 31)),
+		"Completed Date": dateProperty(dateOnly(2025, 10, 16), time.Time{}),
+		"Last Played":    dateProperty(time.Date(2025, 10, 15, 23, 0, 0, 0, time.UTC), time.Time{}),
+	}
+	desired := notionapi.Properties{
+		"Release Date":   dateProperty(clock.Midnight(dateOnly(2025, 10, 1), c), clock.Midnight(dateOnly(2025, 12, 31), c)),
+		"Completed Date": nc.todayProperty(),
+		"Last Played":    dateProperty(time.Date(2025, 10, 15, 19, 0, 0, 0, newYork), time.Time{}),
+	}
+	props, changes := diffProperties(existing, desired, nil, c)
+	if len(props) != 0 || len(changes) != 0 {
+		t.Errorf("diffProperties() = %v, want no changes", changes)
+	}
+
+	desired["Completed Date"] = dateProperty(clock.Midnight(dateOnly(2025, 10, 17), c), time.Time{})
+	_, changes = diffProperties(existing, desired, nil, c)
+	want := []PropertyChange{{Property: "Completed Date", Before: "2025-10-16", After: "2025-10-17"}}
+	if len(changes) != 1 || changes[0] != want[0] {
+		t.Errorf("diffProperties() = %v, want %v", changes, want)
+	}
+}
+
+func TestSteamPropertiesInstallState(t *testing.T) {
+	nc := &NotionClient{clock: clock.Fixed(time.Date(2025, 10, 16, 9, 0, 0, 0, time.UTC))}
+	existing := notionapi.Properties{
+		"Installed": &notionapi.CheckboxProperty{Checkbox: true},
+		"Size":      &notionapi.NumberProperty{Number: 12.5},
+	}
+
+	tests := []struct {
+		name string
+		game steam.SteamGame
+		want []PropertyChange
+	}{
+		{
+			name: "unknown install state leaves the page alone",
+			game: steam.SteamGame{ID: "620"},
+		},
+		{
+			name: "known install state is unchanged",
+			game: steam.SteamGame{ID: "620", InstallStateKnown: true, Installed: true, SizeOnDisk: 12_500_000_000},
+		},
+		{
+			name: "known uninstall is written",
+			game: steam.SteamGame{ID: "620", InstallStateKnown: true},
+			want: []PropertyChange{
+				{Property: "Installed", Before: "true", After: "false"},
+				{Property: "Size", Before: "12.5", After: "0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := nc.steamProperties(tt.game)
+			for name := range desired {
+				if name != "Installed" && name != "Size" {
+					delete(desired, name)
+				}
+			}
+			_, changes := diffProperties(existing, desired, nil, nc.clock)
+			sort.Slice(changes, func(a, b int) bool {
+				return changes[a].Property < changes[b].Property
+			})
+			if !reflect.DeepEqual(changes, tt.want) {
+				t.Errorf("diffProperties() = %v, want %v", changes, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package notion
+
+import (
+	"fmt"
+	"kanbanchan/internal/clock"
+	"kanbanchan/internal/tmdb"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+const StatusWaitingForSeason = "Waiting for Season"
+
+// tvTrackedStatuses are the statuses the progress tracker moves shows
+// between. Shows with any other status, like Dropped, are left alone
+var tvTrackedStatuses = []string{"", StatusWatching, StatusUpNext, StatusFinished, StatusWaitingForSeason}
+
+// TVProperties contains info about pages in the TV database
+type TVProperties struct {
+	PageID        string                         `json:"pageID"`
+	Name          *notionapi.TitleProperty       `json:"name,omitempty"`
+	Status        *notionapi.StatusProperty      `json:"status,omitempty"`
+	TMDBID        *notionapi.RichTextProperty    `json:"tmdbID,omitempty"`
+	Season        *notionapi.NumberProperty      `json:"season,omitempty"`  // the season of the last episode watched
+	Episode       *notionapi.NumberProperty      `json:"episode,omitempty"` // the last episode watched within Season
+	FirstAired    *notionapi.DateProperty        `json:"firstAired,omitempty"`
+	NextEpisode   *notionapi.DateProperty        `json:"nextEpisode,omitempty"`
+	CompletedDate *notionapi.DateProperty        `json:"completedDate,omitempty"`
+	Genres        *notionapi.MultiSelectProperty `json:"genres,omitempty"`
+	properties    notionapi.Properties           // every property on the page, for diffing
+}
+
+// Title returns the plain text title of a show page
+func (tp TVProperties) Title() string {
+	if tp.Name == nil {
+		return ""
+	}
+	return plainText(tp.Name.Title)
+}
+
+// TMDBIDValue returns the TMDB ID a show page is linked to, if any
+func (tp TVProperties) TMDBIDValue() string {
+	if tp.TMDBID == nil {
+		return ""
+	}
+	return strings.TrimSpace(plainText(tp.TMDBID.RichText))
+}
+
+// FirstAirYear returns the year a show page first aired, or 0 when it doesn't
+// have a First Aired date
+func (tp TVProperties) FirstAirYear() int {
+	firstAired, ok := dateStart(tp.FirstAired)
+	if !ok {
+		return 0
+	}
+	return firstAired.Year()
+}
+
+// Progress returns the season and episode of the last episode watched, or
+// zeroes when none have been
+func (tp TVProperties) Progress() (int, int) {
+	if tp.Season == nil || tp.Episode == nil {
+		return 0, 0
+	}
+	return int(tp.Season.Number), int(tp.Episode.Number)
+}
+
+// statusName returns the status of a show page, or an empty string when it
+// doesn't have one
+func (tp TVProperties) statusName() string {
+	if tp.Status == nil {
+		return ""
+	}
+	return tp.Status.Status.Name
+}
+
+// GetTVPages retrieves all pages in the TV DB keyed by title. Only the first
+// page with a given title is kept; use ListTVPages to get every page
+func (nc *NotionClient) GetTVPages(options *notionapi.DatabaseQueryRequest) (*map[string]TVProperties, error) {
+	pages, err := nc.ListTVPages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	shows := make(map[string]TVProperties)
+	for _, page := range pages {
+		_, ok := shows[page.Title()]
+		if !ok {
+			shows[page.Title()] = page
+		}
+	}
+	return &shows, nil
+}
+
+// ListTVPages retrieves every page in the TV DB
+func (nc *NotionClient) ListTVPages(options *notionapi.DatabaseQueryRequest) ([]TVProperties, error) {
+	return nc.TV().List(options)
+}
+
+// newTVProperties reads the properties of a page in the TV DB
+func newTVProperties(page notionapi.Page) TVProperties {
+	tp := TVProperties{
+		PageID:     page.ID.String(),
+		properties: page.Properties,
+	}
+	tp.Name, _ = page.Properties["Name"].(*notionapi.TitleProperty)
+	tp.Status, _ = page.Properties["Status"].(*notionapi.StatusProperty)
+	tp.TMDBID, _ = page.Properties["TMDB ID"].(*notionapi.RichTextProperty)
+	tp.Season, _ = page.Properties["Season"].(*notionapi.NumberProperty)
+	tp.Episode, _ = page.Properties["Episode"].(*notionapi.NumberProperty)
+	tp.FirstAired, _ = page.Properties["First Aired"].(*notionapi.DateProperty)
+	tp.NextEpisode, _ = page.Properties["Next Episode"].(*notionapi.DateProperty)
+	tp.CompletedDate, _ = page.Properties["Completed Date"].(*notionapi.DateProperty)
+	tp.Genres, _ = page.Properties["Genres"].(*notionapi.MultiSelectProperty)
+	return tp
+}
+
+// FindTVPage returns the page for a show by TMDB ID, falling back to the
+// title for pages that haven't been linked to TMDB yet
+func FindTVPage(pages []TVProperties, show tmdb.Show) (TVProperties, bool) {
+	for _, page := range pages {
+		if page.TMDBIDValue() == show.ID {
+			return page, true
+		}
+	}
+	for _, page := range pages {
+		if page.TMDBIDValue() == "" && strings.EqualFold(strings.TrimSpace(page.Title()), strings.TrimSpace(show.Title)) {
+			return page, true
+		}
+	}
+	return TVProperties{}, false
+}
+
+// PlanAddShow plans adding a show to the TV DB with no episodes watched.
+// Optional columns the database doesn't have are left out
+func (nc *NotionClient) PlanAddShow(show tmdb.Show) (*Operation, error) {
+	properties := nc.tmdbShowProperties(show, 0, 0)
+	properties["Name"] = &notionapi.TitleProperty{
+		Title: []notionapi.RichText{{
+			Text:      &notionapi.Text{Content: show.Title},
+			PlainText: show.Title,
+		}},
+	}
+	status, _ := nc.showStatus("", show, 0, 0)
+	properties["Status"] = &notionapi.StatusProperty{Status: notionapi.Option{Name: status}}
+
+	op, err := nc.planPageCreate(TV, show.Title, properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add show %s: %s", show.Title, err.Error())
+	}
+	return op, nil
+}
+
+// PlanShowSync plans updating the properties of an existing show page that
+// differ from TMDB, and moving it to the status its progress calls for as
+// episodes air and seasons are announced. Name, Rating, Notes and the
+// episode watched are left alone. Nil is returned when the page is already
+// up to date
+func (nc *NotionClient) PlanShowSync(existing TVProperties, show tmdb.Show) (*Operation, error) {
+	season, episode := existing.Progress()
+	props := nc.tmdbShowProperties(show, season, episode)
+	status, reason := nc.showStatus(existing.statusName(), show, season, episode)
+	return nc.planShowUpdate(existing, props, status, reason)
+}
+
+// PlanShowProgress plans recording the last episode watched of a show, and
+// moving it to Watching, or to Waiting for Season or Finished once it's
+// caught up. Nil is returned when the page is already up to date
+func (nc *NotionClient) PlanShowProgress(existing TVProperties, show tmdb.Show, season int, episode int) (*Operation, error) {
+	err := checkEpisode(show, season, episode)
+	if err != nil {
+		return nil, err
+	}
+	props := nc.tmdbShowProperties(show, season, episode)
+	props["Season"] = &notionapi.NumberProperty{Number: float64(season)}
+	props["Episode"] = &notionapi.NumberProperty{Number: float64(episode)}
+	status, reason := nc.showStatus(StatusWatching, show, season, episode)
+	if reason == "" && status != existing.statusName() {
+		reason = "episode watched"
+	}
+	return nc.planShowUpdate(existing, props, status, reason)
+}
+
+// UpdateShow updates the properties of a page in the TV DB. Optional columns
+// the database doesn't have are left out rather than failing the request
+func (nc *NotionClient) UpdateShow(showID string, props notionapi.Properties) error {
+	return nc.TV().Update(showID, props)
+}
+
+// planShowUpdate plans writing props to a show page, along with status when
+// it differs from the page's. Shows moved to Finished without a Completed
+// Date get today's date
+func (nc *NotionClient) planShowUpdate(existing TVProperties, props notionapi.Properties, status string, reason string) (*Operation, error) {
+	kind := OperationUpdate
+	if status != existing.statusName() {
+		kind = OperationTransition
+		props["Status"] = &notionapi.StatusProperty{Status: notionapi.Option{Name: status}}
+		if status == StatusFinished && (existing.CompletedDate == nil || existing.CompletedDate.Date == nil) {
+			props["Completed Date"] = nc.todayProperty()
+		}
+	}
+	op, err := nc.planPageUpdate(kind, TV, existing.PageID, existing.properties, props, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan %s of show %s: %s", kind, existing.Title(), err.Error())
+	}
+	if op != nil {
+		op.Title = existing.Title()
+		if kind == OperationTransition {
+			op.Reason = reason
+		}
+	}
+	return op, nil
+}
+
+// showStatus returns the status a show with the given status should move to
+// now that the given episode has been watched, along with why. Shows that
+// aren't tracked, or don't need to move, keep their status and get no reason:
+//   - caught up with a show that has ended: Finished
+//   - caught up mid-season: Watching
+//   - caught up otherwise: Waiting for Season
+//   - new episodes aired while Finished or Waiting for Season: Up Next
+//   - nothing aired yet: Waiting for Season
+//
+// Finished and Waiting for Season shows without any episode recorded as
+// watched are left alone, since there's no telling what's new to them
+func (nc *NotionClient) showStatus(status string, show tmdb.Show, season int, episode int) (string, string) {
+	if !containsString(tvTrackedStatuses, status) {
+		return status, ""
+	}
+	next := status
+	reason := ""
+
+	latest := nc.latestEpisode(show)
+	watched := show.EpisodeIndex(season, episode)
+	switch {
+	case latest == nil:
+		next, reason = StatusWaitingForSeason, "not aired yet"
+	case watched >= show.EpisodeIndex(latest.Season, latest.Number):
+		if show.Ended() {
+			next, reason = StatusFinished, "watched the final episode"
+		} else if upcoming := nc.upcomingEpisode(show); !show.SeasonFinale(*latest) || (upcoming != nil && upcoming.Season == latest.Season) {
+			next, reason = StatusWatching, "caught up mid-season"
+		} else {
+			next, reason = StatusWaitingForSeason, "caught up"
+		}
+	case watched > 0 && (status == StatusFinished || status == StatusWaitingForSeason):
+		next, reason = StatusUpNext, fmt.Sprintf("%s aired", latest.Code())
+	case status == "" && watched > 0:
+		next = StatusWatching
+	case status == "":
+		next = StatusUpNext
+	}
+
+	if next == status {
+		return status, ""
+	}
+	return next, reason
+}
+
+// latestEpisode returns the latest episode of a show that has aired in the
+// client's time zone, which may be the next episode TMDB reports once its air
+// date arrives
+func (nc *NotionClient) latestEpisode(show tmdb.Show) *tmdb.Episode {
+	// TMDB air dates are date-only, midnight UTC, so the episode airs on the
+	// day TMDB names wherever the user is
+	if show.NextEpisode != nil && !show.NextEpisode.AirDate.IsZero() && clock.OnOrBeforeToday(show.NextEpisode.AirDate, nc.clock) {
+		return show.NextEpisode
+	}
+	return show.LastEpisode
+}
+
+// upcomingEpisode returns the next episode of a show that hasn't aired yet in
+// the client's time zone, if one has been announced
+func (nc *NotionClient) upcomingEpisode(show tmdb.Show) *tmdb.Episode {
+	if show.NextEpisode == nil || nc.latestEpisode(show) == show.NextEpisode {
+		return nil
+	}
+	return show.NextEpisode
+}
+
+// checkEpisode returns an error when a show doesn't have an episode
+func checkEpisode(show tmdb.Show, season int, episode int) error {
+	for _, s := range show.Seasons {
+		if s.Number == season {
+			if episode < 1 || (s.EpisodeCount > 0 && episode > s.EpisodeCount) {
+				return fmt.Errorf("%s season %d has %d episodes, not %d", show.Title, season, s.EpisodeCount, episode)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no season %d", show.Title, season)
+}
+
+// tmdbShowProperties returns every property kanbanchan keeps in sync with
+// TMDB for a show, along with how many aired episodes are left after the
+// given one. Optional properties are only included when TMDB has a value
+func (nc *NotionClient) tmdbShowProperties(show tmdb.Show, season int, episode int) notionapi.Properties {
+	properties := notionapi.Properties{
+		"TMDB ID":        &notionapi.RichTextProperty{RichText: richText(show.ID)},
+		"TMDB Page":      &notionapi.URLProperty{URL: show.URL},
+		"Genres":         &notionapi.MultiSelectProperty{MultiSelect: selectOptions(show.Genres)},
+		"Network":        &notionapi.MultiSelectProperty{MultiSelect: selectOptions(show.Networks)},
+		"Where to Watch": &notionapi.MultiSelectProperty{MultiSelect: selectOptions(show.WatchProviders)},
+		"Description":    &notionapi.RichTextProperty{RichText: richText(show.Overview)},
+		"Seasons":        &notionapi.NumberProperty{Number: float64(len(show.Seasons))},
+		"Next Episode":   &notionapi.DateProperty{Date: nil}, // cleared once it airs
+	}
+	if show.PosterURL != "" {
+		properties["Poster"] = &notionapi.FilesProperty{
+			Files: []notionapi.File{{
+				Name:     show.PosterURL,
+				Type:     notionapi.FileTypeExternal,
+				External: &notionapi.FileObject{URL: show.PosterURL},
+			}},
+		}
+	}
+	if show.Status != "" {
+		properties["Show Status"] = &notionapi.SelectProperty{Select: notionapi.Option{Name: show.Status}}
+	}
+	if !show.FirstAirDate.IsZero() {
+		properties["First Aired"] = nc.dayProperty(show.FirstAirDate)
+	}
+	if latest := nc.latestEpisode(show); latest != nil {
+		aired := show.EpisodeIndex(latest.Season, latest.Number)
+		left := aired - show.EpisodeIndex(season, episode)
+		if left < 0 {
+			left = 0
+		}
+		properties["Latest Episode"] = &notionapi.RichTextProperty{RichText: richText(latest.Code())}
+		properties["Episodes"] = &notionapi.NumberProperty{Number: float64(aired)}
+		properties["Episodes Left"] = &notionapi.NumberProperty{Number: float64(left)}
+	}
+	if upcoming := nc.upcomingEpisode(show); upcoming != nil && !upcoming.AirDate.IsZero() {
+		properties["Next Episode"] = nc.dayProperty(upcoming.AirDate)
+	}
+	return properties
+}
@@ -0,0 +1,160 @@
+package notion
+
+import (
+	"kanbanchan/internal/clock"
+	"kanbanchan/internal/tmdb"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+// testShow returns a returning show with two full seasons and a third of
+// unknown length, that has aired up to last and announced next
+func testShow(last *tmdb.Episode, next *tmdb.Episode) tmdb.Show {
+	return tmdb.Show{
+		ID:     "1399",
+		Title:  "Test Show",
+		Status: "Returning Series",
+		Seasons: []tmdb.Season{
+			{Number: 1, EpisodeCount: 10},
+			{Number: 2, EpisodeCount: 8},
+			{Number: 3},
+		},
+		LastEpisode: last,
+		NextEpisode: next,
+	}
+}
+
+func episode(season int, number int, airDate time.Time) *tmdb.Episode {
+	return &tmdb.Episode{Season: season, Number: number, AirDate: airDate}
+}
+
+func TestShowStatus(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	c := clock.Fixed(time.Date(2026, 10, 16, 9, 0, 0, 0, newYork))
+	nc := &NotionClient{clock: c}
+
+	yesterday := dateOnly(2026, 10, 15)
+	today := dateOnly(2026, 10, 16)
+	nextWeek := dateOnly(2026, 10, 23)
+
+	midSeason := testShow(episode(2, 4, yesterday), episode(2, 5, nextWeek))
+	finale := testShow(episode(2, 8, yesterday), nil)
+	finaleAnnounced := testShow(episode(2, 8, yesterday), episode(3, 1, nextWeek))
+	newSeason := testShow(episode(3, 1, yesterday), episode(3, 2, nextWeek))
+	unknownLength := testShow(episode(3, 2, yesterday), episode(3, 3, nextWeek))
+	unknownLengthUnannounced := testShow(episode(3, 2, yesterday), nil)
+	airsToday := testShow(episode(2, 3, yesterday), episode(2, 4, today))
+	notAired := testShow(nil, episode(1, 1, nextWeek))
+	ended := testShow(episode(2, 8, yesterday), nil)
+	ended.Status = tmdb.ShowEnded
+	canceled := testShow(episode(2, 8, yesterday), nil)
+	canceled.Status = tmdb.ShowCanceled
+
+	tests := []struct {
+		name       string
+		status     string
+		show       tmdb.Show
+		season     int
+		episode    int
+		wantStatus string
+		wantReason string
+	}{
+		// Untracked statuses are left alone whatever has aired
+		{name: "dropped", status: "Dropped", show: newSeason, season: 1, episode: 3, wantStatus: "Dropped"},
+		{name: "dropped and caught up", status: "Dropped", show: ended, season: 2, episode: 8, wantStatus: "Dropped"},
+
+		// Nothing has aired
+		{name: "not aired yet", status: "", show: notAired, wantStatus: StatusWaitingForSeason, wantReason: "not aired yet"},
+		{name: "not aired yet while waiting", status: StatusWaitingForSeason, show: notAired, wantStatus: StatusWaitingForSeason},
+
+		// Caught up
+		{name: "caught up with an ended show", status: StatusWatching, show: ended, season: 2, episode: 8, wantStatus: StatusFinished, wantReason: "watched the final episode"},
+		{name: "caught up with a canceled show", status: StatusWatching, show: canceled, season: 2, episode: 8, wantStatus: StatusFinished, wantReason: "watched the final episode"},
+		{name: "already finished an ended show", status: StatusFinished, show: ended, season: 2, episode: 8, wantStatus: StatusFinished},
+		{name: "caught up mid-season", status: StatusUpNext, show: midSeason, season: 2, episode: 4, wantStatus: StatusWatching, wantReason: "caught up mid-season"},
+		{name: "caught up mid-season while watching", status: StatusWatching, show: midSeason, season: 2, episode: 4, wantStatus: StatusWatching},
+		{name: "caught up mid-season of unknown length", status: StatusUpNext, show: unknownLength, season: 3, episode: 2, wantStatus: StatusWatching, wantReason: "caught up mid-season"},
+		{name: "caught up with a season of unknown length", status: StatusWatching, show: unknownLengthUnannounced, season: 3, episode: 2, wantStatus: StatusWaitingForSeason, wantReason: "caught up"},
+		{name: "caught up at the season finale", status: StatusWatching, show: finale, season: 2, episode: 8, wantStatus: StatusWaitingForSeason, wantReason: "caught up"},
+		{name: "caught up with the next season announced", status: StatusWatching, show: finaleAnnounced, season: 2, episode: 8, wantStatus: StatusWaitingForSeason, wantReason: "caught up"},
+
+		// New episodes aired
+		{name: "new season while waiting", status: StatusWaitingForSeason, show: newSeason, season: 2, episode: 8, wantStatus: StatusUpNext, wantReason: "S03E01 aired"},
+		{name: "new season while finished", status: StatusFinished, show: newSeason, season: 2, episode: 8, wantStatus: StatusUpNext, wantReason: "S03E01 aired"},
+		{name: "episode airing today", status: StatusWaitingForSeason, show: airsToday, season: 2, episode: 3, wantStatus: StatusUpNext, wantReason: "S02E04 aired"},
+		{name: "finished with nothing watched", status: StatusFinished, show: newSeason, wantStatus: StatusFinished},
+		{name: "waiting with nothing watched", status: StatusWaitingForSeason, show: newSeason, wantStatus: StatusWaitingForSeason},
+		{name: "behind while watching", status: StatusWatching, show: newSeason, season: 1, episode: 3, wantStatus: StatusWatching},
+		{name: "behind while up next", status: StatusUpNext, show: newSeason, season: 1, episode: 3, wantStatus: StatusUpNext},
+
+		// New pages
+		{name: "new page part way through", status: "", show: newSeason, season: 1, episode: 3, wantStatus: StatusWatching},
+		{name: "new page not started", status: "", show: newSeason, wantStatus: StatusUpNext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := nc.showStatus(tt.status, tt.show, tt.season, tt.episode)
+			if status != tt.wantStatus || reason != tt.wantReason {
+				t.Errorf("showStatus() = %q, %q, want %q, %q", status, reason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTMDBShowProperties(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	c := clock.Fixed(time.Date(2026, 10, 16, 9, 0, 0, 0, newYork))
+	nc := &NotionClient{clock: c}
+
+	yesterday := dateOnly(2026, 10, 15)
+	today := dateOnly(2026, 10, 16)
+	tomorrow := dateOnly(2026, 10, 17)
+
+	tests := []struct {
+		name            string
+		show            tmdb.Show
+		season          int
+		episode         int
+		wantLatest      string // "" when there's no Latest Episode
+		wantEpisodes    float64
+		wantLeft        float64
+		wantNextEpisode string // "" when Next Episode is cleared
+	}{
+		{name: "next episode announced", show: testShow(episode(2, 3, yesterday), episode(2, 4, tomorrow)), season: 1, episode: 5, wantLatest: "S02E03", wantEpisodes: 13, wantLeft: 8, wantNextEpisode: "2026-10-17"},
+		{name: "next episode airs today", show: testShow(episode(2, 3, yesterday), episode(2, 4, today)), season: 2, episode: 3, wantLatest: "S02E04", wantEpisodes: 14, wantLeft: 1},
+		{name: "next episode date unannounced", show: testShow(episode(2, 3, yesterday), episode(2, 4, time.Time{})), season: 2, episode: 3, wantLatest: "S02E03", wantEpisodes: 13, wantLeft: 0},
+		{name: "nothing watched", show: testShow(episode(3, 2, yesterday), nil), wantLatest: "S03E02", wantEpisodes: 20, wantLeft: 20},
+		{name: "watched past the latest", show: testShow(episode(2, 3, yesterday), nil), season: 2, episode: 8, wantLatest: "S02E03", wantEpisodes: 13, wantLeft: 0},
+		{name: "not aired yet", show: testShow(nil, episode(1, 1, tomorrow)), wantNextEpisode: "2026-10-17"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := nc.tmdbShowProperties(tt.show, tt.season, tt.episode)
+
+			latest, _ := props["Latest Episode"].(*notionapi.RichTextProperty)
+			if tt.wantLatest == "" {
+				if latest != nil {
+					t.Errorf("Latest Episode = %q, want none", plainText(latest.RichText))
+				}
+			} else if latest == nil || plainText(latest.RichText) != tt.wantLatest {
+				t.Errorf("Latest Episode = %v, want %q", latest, tt.wantLatest)
+			}
+			if tt.wantLatest != "" {
+				episodes, _ := props["Episodes"].(*notionapi.NumberProperty)
+				left, _ := props["Episodes Left"].(*notionapi.NumberProperty)
+				if episodes == nil || left == nil || episodes.Number != tt.wantEpisodes || left.Number != tt.wantLeft {
+					t.Errorf("Episodes, Episodes Left = %v, %v, want %v, %v", episodes, left, tt.wantEpisodes, tt.wantLeft)
+				}
+			}
+
+			next, _ := props["Next Episode"].(*notionapi.DateProperty)
+			if got := propertyValue(next, c); got != tt.wantNextEpisode {
+				t.Errorf("Next Episode = %q, want %q", got, tt.wantNextEpisode)
+			}
+		})
+	}
+}
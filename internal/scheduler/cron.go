@@ -0,0 +1,236 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearch bounds how far ahead Next looks for a matching time, so
+// expressions like "0 0 30 2 *" that never match don't loop forever
+const maxCronSearch = 5 * 365 * 24 * time.Hour
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// CronSchedule is a parsed five field cron expression
+type CronSchedule struct {
+	expr     string
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// cronField describes the allowed values of a cron field
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+// ParseCron parses a standard cron expression of minute, hour, day of month,
+// month and day of week fields, or one of the @hourly, @daily, @weekly,
+// @monthly and @yearly descriptors. Fields accept *, lists, ranges, steps and
+// three letter month and day names. Times are matched in the local time zone
+func ParseCron(expr string) (*CronSchedule, error) {
+	expanded := strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expanded)]; ok {
+		expanded = descriptor
+	}
+
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	schedule := CronSchedule{expr: expr, location: time.Local}
+	var err error
+	specs := []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{cronField{name: "minute", min: 0, max: 59}, &schedule.minute},
+		{cronField{name: "hour", min: 0, max: 23}, &schedule.hour},
+		{cronField{name: "day of month", min: 1, max: 31}, &schedule.dom},
+		{cronField{name: "month", min: 1, max: 12, names: monthNames}, &schedule.month},
+		{cronField{name: "day of week", min: 0, max: 7, names: dayNames}, &schedule.dow},
+	}
+	for i, spec := range specs {
+		*spec.bits, err = parseCronField(fields[i], spec.field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", expr, err.Error())
+		}
+	}
+	if schedule.dow&(1<<7) != 0 { // 7 is also Sunday
+		schedule.dow |= 1
+	}
+	// Like cron, a field starting with * such as */2 counts as unrestricted
+	// when deciding how the day fields combine
+	schedule.domStar = strings.HasPrefix(fields[2], "*")
+	schedule.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return &schedule, nil
+}
+
+// In returns a copy of the schedule that matches times in loc
+func (cs *CronSchedule) In(loc *time.Location) *CronSchedule {
+	schedule := *cs
+	schedule.location = loc
+	return &schedule
+}
+
+func (cs *CronSchedule) String() string {
+	return cs.expr
+}
+
+// Next returns the first time after t that matches the schedule, or the zero
+// time when nothing matches within the next five years. Times skipped when
+// clocks go forward, like 02:30 on the day daylight saving time starts, run
+// at the moment the clocks jump
+func (cs *CronSchedule) Next(t time.Time) time.Time {
+	t = t.In(cs.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+	for t.Before(limit) {
+		if cs.skippedMatch(t) {
+			return t
+		}
+		if cs.month&(1<<uint(t.Month())) == 0 {
+			t = cs.date(t.Year(), t.Month()+1, 1, 0)
+			continue
+		}
+		if !cs.dayMatches(t) {
+			t = cs.date(t.Year(), t.Month(), t.Day()+1, 0)
+			continue
+		}
+		if cs.hour&(1<<uint(t.Hour())) == 0 {
+			t = cs.date(t.Year(), t.Month(), t.Day(), t.Hour()+1)
+			continue
+		}
+		if cs.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// date returns the start of an hour in the schedule's zone. When clocks
+// jumped forward over it, the moment they jumped is returned instead, since
+// Go resolves a skipped time with the offset from before the jump, which
+// would move the search backwards
+func (cs *CronSchedule) date(year int, month time.Month, day int, hour int) time.Time {
+	t := time.Date(year, month, day, hour, 0, 0, 0, cs.location)
+	if wallClock(t).Before(time.Date(year, month, day, hour, 0, 0, 0, time.UTC)) {
+		_, t = t.ZoneBounds()
+	}
+	return t
+}
+
+// skippedMatch reports whether clocks jumped forward to t over a wall clock
+// time the schedule matches
+func (cs *CronSchedule) skippedMatch(t time.Time) bool {
+	end := wallClock(t)
+	for wall := wallClock(t.Add(-time.Minute)).Add(time.Minute); wall.Before(end); wall = wall.Add(time.Minute) {
+		if cs.month&(1<<uint(wall.Month())) != 0 && cs.dayMatches(wall) &&
+			cs.hour&(1<<uint(wall.Hour())) != 0 && cs.minute&(1<<uint(wall.Minute())) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wallClock returns the date and time t reads on a clock in its zone, as UTC
+// so the minutes between two readings can be counted
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// dayMatches follows cron in matching either day field when both are
+// restricted, and only the restricted one otherwise
+func (cs *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cs.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) != 0
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps
+// into a bitset of matching values
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, field.name)
+			}
+		}
+
+		start, end := field.min, field.max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			start, err = cronValue(startPart, field)
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				end, err = cronValue(endPart, field)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = field.max
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q in %s field", rangePart, field.name)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(value string, field cronField) (int, error) {
+	if n, ok := field.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < field.min || n > field.max {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, field.name)
+	}
+	return n, nil
+}
@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 9-17 * * mon-fri"},
+		{expr: "0 0 1,15 jan-mar *"},
+		{expr: "5/20 * * * *"},
+		{expr: "0 0 * * 7"},
+		{expr: "0 0 * * 0-7"},
+		{expr: "  @Daily  "},
+		{expr: "@weekly"},
+		{expr: "0 0 * *", wantErr: true},
+		{expr: "0 0 * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "*/x * * * *", wantErr: true},
+		{expr: "a * * * *", wantErr: true},
+		{expr: "* * * foo *", wantErr: true},
+		{expr: "@every 5m", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCron(%q) = %+v, want an error", tt.expr, schedule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %s", tt.expr, err.Error())
+			}
+			if schedule.String() != tt.expr {
+				t.Errorf("String() = %q, want %q", schedule.String(), tt.expr)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data isn't available: %s", err.Error())
+	}
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skipf("time zone data isn't available: %s", err.Error())
+	}
+	utc := time.UTC
+
+	tests := []struct {
+		name     string
+		expr     string
+		location *time.Location
+		from     time.Time
+		want     time.Time // zero when nothing matches
+	}{
+		{
+			name: "next minute",
+			expr: "* * * * *", location: utc,
+			from: time.Date(2026, 10, 16, 12, 30, 45, 0, utc),
+			want: time.Date(2026, 10, 16, 12, 31, 0, 0, utc),
+		},
+		{
+			name: "strictly after a matching time",
+			expr: "30 12 * * *", location: utc,
+			from: time.Date(2026, 10, 16, 12, 30, 0, 0, utc),
+			want: time.Date(2026, 10, 17, 12, 30, 0, 0, utc),
+		},
+		{
+			name: "steps",
+			expr: "*/15 * * * *", location: utc,
+			from: time.Date(2026, 10, 16, 12, 31, 0, 0, utc),
+			want: time.Date(2026, 10, 16, 12, 45, 0, 0, utc),
+		},
+		{
+			name: "step from a single start value",
+			expr: "5/20 * * * *", location: utc,
+			from: time.Date(2026, 10, 16, 12, 26, 0, 0, utc),
+			want: time.Date(2026, 10, 16, 12, 45, 0, 0, utc),
+		},
+		{
+			name: "step from a single start value wraps to the next hour",
+			expr: "5/20 * * * *", location: utc,
+			from: time.Date(2026, 10, 16, 12, 46, 0, 0, utc),
+			want: time.Date(2026, 10, 16, 13, 5, 0, 0, utc),
+		},
+		{
+			name: "weekdays skip the weekend",
+			expr: "0 9 * * mon-fri", location: utc,
+			from: time.Date(2026, 10, 16, 10, 0, 0, 0, utc), // a Friday
+			want: time.Date(2026, 10, 19, 9, 0, 0, 0, utc),
+		},
+		{
+			name: "7 is Sunday",
+			expr: "0 0 * * 7", location: utc,
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 18, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "0 is Sunday",
+			expr: "0 0 * * sun", location: utc,
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 18, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "restricted day of month and week match either",
+			expr: "0 0 13 * fri", location: utc,
+			from: time.Date(2026, 10, 10, 0, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 13, 0, 0, 0, 0, utc), // a Tuesday, the 13th
+		},
+		{
+			name: "restricted day of week matches before day of month",
+			expr: "0 0 20 * fri", location: utc,
+			from: time.Date(2026, 10, 13, 0, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "unrestricted day of week only matches day of month",
+			expr: "0 0 20 * *", location: utc,
+			from: time.Date(2026, 10, 13, 0, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 20, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "stepped day of month counts as unrestricted",
+			expr: "0 0 */2 * mon", location: utc,
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 19, 0, 0, 0, 0, utc), // not the 17th, an odd Saturday
+		},
+		{
+			name: "month names",
+			expr: "0 0 1 jan *", location: utc,
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+			want: time.Date(2027, 1, 1, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "leap day",
+			expr: "0 0 29 2 *", location: utc,
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+			want: time.Date(2028, 2, 29, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "never matches",
+			expr: "0 0 30 2 *", location: utc,
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "matched in the schedule's zone",
+			expr: "0 9 * * *", location: newYork,
+			from: time.Date(2026, 10, 16, 12, 0, 0, 0, utc),
+			want: time.Date(2026, 10, 16, 13, 0, 0, 0, utc),
+		},
+		{
+			name: "time skipped by daylight saving runs when clocks jump",
+			expr: "30 2 * * *", location: newYork,
+			from: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			want: time.Date(2026, 3, 8, 3, 0, 0, 0, newYork),
+		},
+		{
+			name: "time skipped by daylight saving from within the previous hour",
+			expr: "30 2 * * *", location: newYork,
+			from: time.Date(2026, 3, 8, 1, 59, 0, 0, newYork),
+			want: time.Date(2026, 3, 8, 3, 0, 0, 0, newYork),
+		},
+		{
+			name: "time skipped by daylight saving runs at its usual time the next day",
+			expr: "30 2 * * *", location: newYork,
+			from: time.Date(2026, 3, 8, 3, 0, 0, 0, newYork),
+			want: time.Date(2026, 3, 9, 2, 30, 0, 0, newYork),
+		},
+		{
+			name: "time after the daylight saving jump",
+			expr: "30 3 * * *", location: newYork,
+			from: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			want: time.Date(2026, 3, 8, 3, 30, 0, 0, newYork),
+		},
+		{
+			name: "midnight skipped by daylight saving runs when clocks jump",
+			expr: "@daily", location: santiago,
+			from: time.Date(2026, 9, 5, 12, 0, 0, 0, santiago),
+			want: time.Date(2026, 9, 6, 1, 0, 0, 0, santiago),
+		},
+		{
+			name: "weekly on a day whose midnight was skipped",
+			expr: "0 12 * * sun", location: santiago,
+			from: time.Date(2026, 9, 5, 12, 0, 0, 0, santiago),
+			want: time.Date(2026, 9, 6, 12, 0, 0, 0, santiago),
+		},
+		{
+			name: "hourly across the daylight saving jump",
+			expr: "@hourly", location: newYork,
+			from: time.Date(2026, 3, 8, 1, 0, 0, 0, newYork),
+			want: time.Date(2026, 3, 8, 3, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %s", tt.expr, err.Error())
+			}
+			got := schedule.In(tt.location).Next(tt.from)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
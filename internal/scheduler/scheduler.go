@@ -0,0 +1,323 @@
+// Package scheduler runs jobs on cron schedules, persisting when each job
+// last ran so the state survives restarts.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrPartial is returned by a job that finished but had to skip some of its
+// work, like games Steam wouldn't return. The run is recorded as partial
+// rather than failed
+var ErrPartial = errors.New("finished with some items skipped")
+
+// Job is a task run on a cron schedule
+type Job struct {
+	Name     string
+	Schedule *CronSchedule
+	Run      func(ctx context.Context) error
+}
+
+// JobState is the persisted state of a job
+type JobState struct {
+	Schedule     string    `json:"schedule"`
+	LastRun      time.Time `json:"lastRun"`
+	LastDuration string    `json:"lastDuration,omitempty"`
+	LastError    string    `json:"lastError,omitempty"`
+	LastPartial  bool      `json:"lastPartial,omitempty"` // the last run finished but skipped some of its work
+	NextRun      time.Time `json:"nextRun"`
+	Running      bool      `json:"running"`
+	Queued       bool      `json:"queued,omitempty"`  // due, but waiting for another job to finish
+	Skipped      int       `json:"skipped,omitempty"` // runs skipped because the previous one hadn't finished
+}
+
+// Scheduler runs jobs on their schedules, never running a job while its
+// previous run is still going. Jobs share clients that aren't safe to use
+// from several runs at once, so only one job runs at a time and a job that
+// comes due while another is running waits for it to finish
+type Scheduler struct {
+	jobs      []*scheduledJob
+	statePath string
+	started   time.Time
+
+	mu     sync.Mutex
+	state  map[string]JobState
+	wg     sync.WaitGroup
+	saveMu sync.Mutex // serializes writes to the state file
+	runMu  sync.Mutex // held by whichever job is running
+}
+
+type scheduledJob struct {
+	Job
+	lock sync.Mutex // held while the job runs
+}
+
+// New creates a scheduler for jobs, loading any state saved at statePath by
+// a previous run. An empty statePath doesn't persist state
+func New(statePath string, jobs ...Job) (*Scheduler, error) {
+	scheduler := Scheduler{
+		statePath: statePath,
+		state:     make(map[string]JobState),
+	}
+	names := make(map[string]bool)
+	for _, job := range jobs {
+		if names[job.Name] {
+			return nil, fmt.Errorf("duplicate job %s", job.Name)
+		}
+		names[job.Name] = true
+		scheduler.jobs = append(scheduler.jobs, &scheduledJob{Job: job})
+	}
+
+	if statePath != "" {
+		fileContent, err := os.ReadFile(statePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read scheduler state: %s", err.Error())
+		}
+		if err == nil {
+			err = json.Unmarshal(fileContent, &scheduler.state)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse scheduler state %s: %s", statePath, err.Error())
+			}
+		}
+	}
+	// Forget jobs that were removed from the config since the state was saved
+	for name := range scheduler.state {
+		if !names[name] {
+			delete(scheduler.state, name)
+		}
+	}
+
+	return &scheduler, nil
+}
+
+// Run starts jobs as they come due until ctx is cancelled, then waits for
+// running jobs to finish. Jobs are passed ctx so they can stop early
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	s.started = time.Now()
+	for _, job := range s.jobs {
+		state := s.state[job.Name]
+		state.Schedule = job.Schedule.String()
+		state.Running = false
+		state.Queued = false
+		state.NextRun = job.Schedule.Next(s.started)
+		s.state[job.Name] = state
+	}
+	s.mu.Unlock()
+	err := s.save()
+	if err != nil {
+		return err
+	}
+
+	for {
+		next := s.nextRun()
+		var timer <-chan time.Time
+		if !next.IsZero() {
+			timer = time.After(time.Until(next))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return s.save()
+		case now := <-timer:
+			for _, job := range s.jobs {
+				if s.due(job, now) {
+					s.start(ctx, job, now)
+				}
+			}
+			err = s.save()
+			if err != nil {
+				log.Printf("failed to save scheduler state: %s", err.Error())
+			}
+		}
+	}
+}
+
+// State returns the current state of every job keyed by job name
+func (s *Scheduler) State() map[string]JobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := make(map[string]JobState, len(s.state))
+	for name, jobState := range s.state {
+		state[name] = jobState
+	}
+	return state
+}
+
+// HealthHandler reports the scheduler's uptime and the state of every job as JSON
+func (s *Scheduler) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		started := s.started
+		s.mu.Unlock()
+
+		health := struct {
+			Status string              `json:"status"`
+			Uptime string              `json:"uptime"`
+			Jobs   map[string]JobState `json:"jobs"`
+		}{
+			Status: "ok",
+			Uptime: time.Since(started).Round(time.Second).String(),
+			Jobs:   s.State(),
+		}
+		for _, job := range health.Jobs {
+			if job.LastError != "" {
+				health.Status = "degraded"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(health)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// nextRun returns the earliest time a job is due
+func (s *Scheduler) nextRun() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, state := range s.state {
+		if !state.NextRun.IsZero() && (next.IsZero() || state.NextRun.Before(next)) {
+			next = state.NextRun
+		}
+	}
+	return next
+}
+
+// due reports whether a job should run at now, moving its next run forward when it is
+func (s *Scheduler) due(job *scheduledJob, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state[job.Name]
+	if state.NextRun.IsZero() || now.Before(state.NextRun) {
+		return false
+	}
+	state.NextRun = job.Schedule.Next(now)
+	s.state[job.Name] = state
+	return true
+}
+
+// start runs a job in the background unless its previous run is still going,
+// queueing it behind any other job that is running
+func (s *Scheduler) start(ctx context.Context, job *scheduledJob, now time.Time) {
+	if !job.lock.TryLock() {
+		s.mu.Lock()
+		state := s.state[job.Name]
+		state.Skipped++
+		s.state[job.Name] = state
+		s.mu.Unlock()
+		log.Printf("skipping %s, the previous run hasn't finished", job.Name)
+		return
+	}
+
+	s.mu.Lock()
+	state := s.state[job.Name]
+	state.Queued = true
+	s.state[job.Name] = state
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer job.lock.Unlock()
+
+		s.runMu.Lock()
+		defer s.runMu.Unlock()
+		s.mu.Lock()
+		state := s.state[job.Name]
+		state.Queued = false
+		state.Running = ctx.Err() == nil
+		if state.Running {
+			state.LastRun = now
+		}
+		s.state[job.Name] = state
+		s.mu.Unlock()
+		if !state.Running {
+			return // stopped while waiting for another job
+		}
+
+		log.Printf("running %s", job.Name)
+		started := time.Now()
+		err := runJob(ctx, job.Job)
+		duration := time.Since(started).Round(time.Millisecond)
+
+		s.mu.Lock()
+		state = s.state[job.Name]
+		state.Running = false
+		state.LastDuration = duration.String()
+		state.LastError = ""
+		state.LastPartial = errors.Is(err, ErrPartial)
+		if err != nil && !state.LastPartial {
+			state.LastError = err.Error()
+		}
+		s.state[job.Name] = state
+		s.mu.Unlock()
+
+		if state.LastPartial {
+			log.Printf("%s finished in %s but skipped some items", job.Name, duration)
+		} else if err != nil {
+			log.Printf("%s failed after %s: %s", job.Name, duration, err.Error())
+		} else {
+			log.Printf("%s finished in %s", job.Name, duration)
+		}
+		err = s.save()
+		if err != nil {
+			log.Printf("failed to save scheduler state: %s", err.Error())
+		}
+	}()
+}
+
+// runJob runs a job, turning a panic into an error so one bad run doesn't
+// take down the scheduler
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// save writes the state of every job to the state file, replacing it atomically
+func (s *Scheduler) save() error {
+	if s.statePath == "" {
+		return nil
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	fileContent, err := json.MarshalIndent(s.state, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.statePath), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to save scheduler state: %s", err.Error())
+	}
+	tmp := s.statePath + ".tmp"
+	err = os.WriteFile(tmp, fileContent, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to save scheduler state: %s", err.Error())
+	}
+	err = os.Rename(tmp, s.statePath)
+	if err != nil {
+		return fmt.Errorf("failed to save scheduler state: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSchedulerSkipsWhileRunning(t *testing.T) {
+	schedule, err := ParseCron("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	s, err := New(statePath, Job{
+		Name:     "sync",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			started <- struct{}{}
+			<-release
+			return errors.New("steam is down")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	s.start(context.Background(), s.jobs[0], now)
+	<-started
+	s.start(context.Background(), s.jobs[0], now.Add(time.Minute))
+	s.start(context.Background(), s.jobs[0], now.Add(2*time.Minute))
+
+	state := s.State()["sync"]
+	if !state.Running || state.Skipped != 2 || !state.LastRun.Equal(now) {
+		t.Errorf("state while running = %+v, want running since %s with 2 skipped", state, now)
+	}
+
+	close(release)
+	s.wg.Wait()
+	if len(started) != 0 {
+		t.Errorf("a skipped run was started")
+	}
+	state = s.State()["sync"]
+	if state.Running || state.LastError != "steam is down" || state.LastDuration == "" {
+		t.Errorf("state after running = %+v, want the run's error recorded", state)
+	}
+
+	// The next run starts once the previous one has finished
+	release = make(chan struct{})
+	close(release)
+	s.start(context.Background(), s.jobs[0], now.Add(3*time.Minute))
+	s.wg.Wait()
+	if len(started) != 1 {
+		t.Errorf("the run after the previous one finished didn't start")
+	}
+}
+
+func TestSchedulerRunsOneJobAtATime(t *testing.T) {
+	schedule, err := ParseCron("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan string, 2)
+	s, err := New("",
+		Job{
+			Name:     "sync-games",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				started <- "sync-games"
+				<-release
+				return nil
+			},
+		},
+		Job{
+			Name:     "transition",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				started <- "transition"
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	s.start(context.Background(), s.jobs[0], now)
+	if got := <-started; got != "sync-games" {
+		t.Fatalf("started %s, want sync-games", got)
+	}
+	s.start(context.Background(), s.jobs[1], now)
+
+	// The second job waits for the first instead of running alongside it
+	select {
+	case got := <-started:
+		t.Fatalf("%s started while sync-games was running", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	state := s.State()["transition"]
+	if !state.Queued || state.Running {
+		t.Errorf("state while another job runs = %+v, want queued", state)
+	}
+
+	close(release)
+	if got := <-started; got != "transition" {
+		t.Errorf("started %s after sync-games finished, want transition", got)
+	}
+	s.wg.Wait()
+	state = s.State()["transition"]
+	if state.Queued || state.Running || !state.LastRun.Equal(now) {
+		t.Errorf("state after running = %+v, want run at %s", state, now)
+	}
+}
+
+func TestSchedulerDropsQueuedRunsWhenStopped(t *testing.T) {
+	schedule, err := ParseCron("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	ran := make(chan string, 2)
+	s, err := New("",
+		Job{
+			Name:     "sync-games",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				started <- struct{}{}
+				<-release
+				return nil
+			},
+		},
+		Job{
+			Name:     "transition",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				ran <- "transition"
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	s.start(ctx, s.jobs[0], now)
+	<-started
+	s.start(ctx, s.jobs[1], now)
+	cancel()
+	close(release)
+	s.wg.Wait()
+
+	if len(ran) != 0 {
+		t.Errorf("a queued job ran after the scheduler was stopped")
+	}
+	if state := s.State()["transition"]; state.Queued || state.Running || !state.LastRun.IsZero() {
+		t.Errorf("state of the dropped run = %+v, want it not recorded as run", state)
+	}
+}
+
+func TestSchedulerRecoversPanics(t *testing.T) {
+	schedule, err := ParseCron("@hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New("", Job{
+		Name:     "transition",
+		Schedule: schedule,
+		Run:      func(ctx context.Context) error { panic("nil page") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.start(context.Background(), s.jobs[0], time.Now())
+	s.wg.Wait()
+	if got := s.State()["transition"].LastError; got != "panic: nil page" {
+		t.Errorf("LastError = %q, want the panic", got)
+	}
+}
+
+func TestSchedulerRecordsPartialRuns(t *testing.T) {
+	schedule, err := ParseCron("@hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []error{ErrPartial, errors.New("notion is down"), nil}
+	s, err := New("", Job{
+		Name:     "sync-games",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			err := results[0]
+			results = results[1:]
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []JobState{
+		{LastPartial: true},
+		{LastError: "notion is down"},
+		{},
+	}
+	for i, wantState := range want {
+		s.start(context.Background(), s.jobs[0], time.Now())
+		s.wg.Wait()
+		got := s.State()["sync-games"]
+		if got.LastPartial != wantState.LastPartial || got.LastError != wantState.LastError {
+			t.Errorf("state after run %d = %+v, want LastPartial %t and LastError %q", i+1, got, wantState.LastPartial, wantState.LastError)
+		}
+	}
+}
+
+func TestSchedulerState(t *testing.T) {
+	schedule, err := ParseCron("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(t.TempDir(), "state", "state.json")
+	lastRun := time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)
+	saved := map[string]JobState{
+		"sync-games":   {Schedule: "0 3 * * *", LastRun: lastRun, LastError: "failed", Running: true, Skipped: 1},
+		"refresh-list": {Schedule: "@daily", LastRun: lastRun},
+	}
+	fileContent, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(statePath), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(statePath, fileContent, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(statePath, Job{Name: "sync-games", Schedule: schedule, Run: func(ctx context.Context) error { return nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := s.State()
+	if _, ok := state["refresh-list"]; ok {
+		t.Errorf("state of a job removed from the config was kept")
+	}
+	if got := state["sync-games"]; !got.LastRun.Equal(lastRun) || got.LastError != "failed" || got.Skipped != 1 {
+		t.Errorf("loaded state = %+v, want the saved state", got)
+	}
+
+	// Running resets a run interrupted by a restart and schedules the next
+	// one, saving the state before and after
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = s.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %s", err.Error())
+	}
+	fileContent, err = os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var persisted map[string]JobState
+	err = json.Unmarshal(fileContent, &persisted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := persisted["sync-games"]
+	if got.Running || got.NextRun.IsZero() || got.Schedule != "0 3 * * *" || !got.LastRun.Equal(lastRun) {
+		t.Errorf("persisted state = %+v", got)
+	}
+	if _, err := os.Stat(statePath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary state file was left behind")
+	}
+
+	reloaded, err := New(statePath, Job{Name: "sync-games", Schedule: schedule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.State()["sync-games"]; !got.NextRun.Equal(persisted["sync-games"].NextRun) {
+		t.Errorf("reloaded NextRun = %s, want %s", got.NextRun, persisted["sync-games"].NextRun)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	schedule, err := ParseCron("@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = New("", Job{Name: "sync", Schedule: schedule}, Job{Name: "sync", Schedule: schedule})
+	if err == nil {
+		t.Errorf("New() with duplicate jobs should fail")
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	err = os.WriteFile(statePath, []byte("{"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = New(statePath, Job{Name: "sync", Schedule: schedule})
+	if err == nil {
+		t.Errorf("New() with a corrupt state file should fail")
+	}
+}
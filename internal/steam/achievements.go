@@ -0,0 +1,51 @@
+package steam
+
+import (
+	"errors"
+	"kanbanchan/pkg/steam"
+)
+
+// Completion contains a user's achievement progress in a game
+type Completion struct {
+	Achieved int     `json:"achieved"`
+	Total    int     `json:"total"`
+	Percent  float64 `json:"percent"`
+}
+
+// Complete reports whether every achievement has been unlocked
+func (c *Completion) Complete() bool {
+	return c != nil && c.Total > 0 && c.Achieved >= c.Total
+}
+
+// GetCompletion calculates the authenticated user's achievement completion
+// for a game. A nil Completion is returned for games without achievements
+func (sc *SteamClient) GetCompletion(appID string) (*Completion, error) {
+	schema, err := sc.steam.GetSchemaForGame(appID)
+	if err != nil {
+		return nil, err
+	}
+	total := len(schema.Game.AvailableGameStats.Achievements)
+	if total == 0 {
+		return nil, nil
+	}
+
+	achievements, err := sc.steam.GetPlayerAchievements(sc.steamID, appID)
+	if errors.Is(err, steam.ErrNoStats) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	achieved := 0
+	for _, achievement := range achievements.PlayerStats.Achievements {
+		if achievement.Achieved == 1 {
+			achieved++
+		}
+	}
+
+	return &Completion{
+		Achieved: achieved,
+		Total:    total,
+		Percent:  float64(achieved) / float64(total) * 100,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package steam
+
+import (
+	"fmt"
+	"time"
+)
+
+// GameActivity contains when and how much the authenticated user has played a game
+type GameActivity struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	LastPlayed time.Time `json:"lastPlayed"` // zero when never played
+	Playtime   Playtime  `json:"playtime"`
+}
+
+// PlayedRecently reports whether the game was played in the last two weeks
+func (ga GameActivity) PlayedRecently() bool {
+	return ga.Playtime.Recent > 0
+}
+
+// GetActivity gets play activity for every game owned by the authenticated
+// user, keyed by app ID, combining lifetime stats from the library with playtime from the
+// last two weeks
+func (sc *SteamClient) GetActivity() (*map[string]GameActivity, error) {
+	library, err := sc.steam.GetUserOwnedGames(sc.steamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library for user id %s: %s", sc.steamID, err.Error())
+	}
+
+	recent, err := sc.steam.GetRecentlyPlayedGames(sc.steamID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently played games for user id %s: %s", sc.steamID, err.Error())
+	}
+	recentPlaytimes := make(map[string]time.Duration)
+	for _, game := range recent.Response.Games {
+		playtime, err := minutesDuration(game.Playtime2Weeks)
+		if err != nil {
+			return nil, err
+		}
+		recentPlaytimes[game.AppID.String()] = playtime
+	}
+
+	activity := make(map[string]GameActivity)
+	for _, game := range library.Response.Games {
+		playtime, err := newPlaytime(game)
+		if err != nil {
+			return nil, err
+		}
+		playtime.Recent = recentPlaytimes[game.AppID.String()]
+		lastPlayed, err := lastPlayedTime(game.LastPlayed)
+		if err != nil {
+			return nil, err
+		}
+		activity[game.AppID.String()] = GameActivity{
+			ID:         game.AppID.String(),
+			Name:       game.Name,
+			LastPlayed: lastPlayed,
+			Playtime:   playtime,
+		}
+	}
+
+	return &activity, nil
+}
@@ -0,0 +1,199 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kanbanchan/pkg/vdf"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// steamID64Base is subtracted from a 64-bit Steam ID to get the account ID
+// used for the userdata directory
+const steamID64Base = 76561197960265728
+
+// cloudStorageEntry is the value of a single key in the Steam client's
+// cloud-storage-namespace files
+type cloudStorageEntry struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	IsDeleted bool   `json:"is_deleted"`
+}
+
+// cloudCollection is a user collection stored in the cloud-storage-namespace files
+type cloudCollection struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Added   []int  `json:"added"`
+	Removed []int  `json:"removed"`
+}
+
+// ReadLocalCollections reads the user's library collections from the Steam
+// client installed at installPath, returning the app IDs in each collection
+// keyed by collection name. Collections are read from the cloud storage
+// files used by the current client and from the tags in the legacy
+// sharedconfig.vdf, so either may be missing
+func ReadLocalCollections(installPath string, steamID string) (map[string][]string, error) {
+	userDir, err := userDataDir(installPath, steamID)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make(map[string][]string)
+
+	namespaces, err := filepath.Glob(filepath.Join(userDir, "config", "cloudstorage", "cloud-storage-namespace-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range namespaces {
+		err = readCloudStorageCollections(namespace, collections)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read collections from %s: %s", namespace, err.Error())
+		}
+	}
+
+	sharedConfig := filepath.Join(userDir, "7", "remote", "sharedconfig.vdf")
+	err = readSharedConfigCollections(sharedConfig, collections)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read collections from %s: %s", sharedConfig, err.Error())
+	}
+
+	return collections, nil
+}
+
+// readCloudStorageCollections adds the user collections in a cloud-storage-namespace file to collections
+func readCloudStorageCollections(path string, collections map[string][]string) error {
+	var entries [][2]json.RawMessage
+	fileContent, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(fileContent, &entries)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range entries {
+		var entry cloudStorageEntry
+		err = json.Unmarshal(pair[1], &entry)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(entry.Key, "user-collections.") || entry.IsDeleted || entry.Value == "" {
+			continue
+		}
+
+		var collection cloudCollection
+		err = json.Unmarshal([]byte(entry.Value), &collection)
+		if err != nil {
+			return fmt.Errorf("failed to parse collection %s: %s", entry.Key, err.Error())
+		}
+
+		// Dynamic collections have a filter instead of a list of apps, so
+		// there's nothing to read from them
+		removed := make(map[int]bool)
+		for _, appID := range collection.Removed {
+			removed[appID] = true
+		}
+		for _, appID := range collection.Added {
+			if !removed[appID] {
+				collections[collection.Name] = appendUnique(collections[collection.Name], strconv.Itoa(appID))
+			}
+		}
+	}
+
+	return nil
+}
+
+// readSharedConfigCollections adds the per-app tags in a legacy sharedconfig.vdf to collections
+func readSharedConfigCollections(path string, collections map[string][]string) error {
+	fileContent, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	root, err := vdf.Parse(fileContent)
+	if err != nil {
+		return err
+	}
+
+	apps := vdf.Lookup(root, "UserRoamingConfigStore", "Software", "Valve", "Steam", "apps")
+	for appID, value := range apps {
+		app, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		tags := vdf.Lookup(app, "tags")
+		for _, tag := range tags {
+			name, ok := tag.(string)
+			if ok {
+				collections[name] = appendUnique(collections[name], appID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// userDataDir returns the Steam client's userdata directory for a 64-bit Steam ID
+func userDataDir(installPath string, steamID string) (string, error) {
+	accountID, err := accountIDFromSteamID(steamID)
+	if err != nil {
+		return "", err
+	}
+	userDir := filepath.Join(installPath, "userdata", accountID)
+	_, err = os.Stat(userDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find steam userdata for account id %s: %s", accountID, err.Error())
+	}
+	return userDir, nil
+}
+
+// accountIDFromSteamID converts a 64-bit Steam ID to the 32-bit account ID
+func accountIDFromSteamID(steamID string) (string, error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(steamID), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse steam id %s: %s", steamID, err.Error())
+	}
+	if id < steamID64Base {
+		return strconv.FormatUint(id, 10), nil // already an account ID
+	}
+	return strconv.FormatUint(id-steamID64Base, 10), nil
+}
+
+// defaultInstallPath returns where the Steam client is usually installed on
+// this OS, or an empty string when it isn't there
+func defaultInstallPath() string {
+	home, _ := os.UserHomeDir()
+	var candidates []string
+	switch runtime.GOOS {
+	case "windows":
+		candidates = []string{`C:\Program Files (x86)\Steam`, `C:\Program Files\Steam`}
+	case "darwin":
+		candidates = []string{filepath.Join(home, "Library", "Application Support", "Steam")}
+	default:
+		candidates = []string{
+			filepath.Join(home, ".steam", "steam"),
+			filepath.Join(home, ".local", "share", "Steam"),
+			filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
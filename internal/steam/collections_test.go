@@ -0,0 +1,85 @@
+package steam
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReadLocalCollections(t *testing.T) {
+	tests := []struct {
+		name        string
+		installPath string
+		steamID     string
+		want        map[string][]string
+		wantErr     bool
+	}{
+		{
+			name:        "cloud storage and sharedconfig",
+			installPath: "testdata/steam",
+			steamID:     "76561197960278073",
+			want: map[string][]string{
+				"Finished":    {"400", "620"},
+				"Up \"Next\"": {"730"},
+				"favorite":    {"400"},
+				"Playing":     {"70"},
+			},
+		},
+		{
+			name:        "account id instead of steam id",
+			installPath: "testdata/steam",
+			steamID:     "12345",
+			want: map[string][]string{
+				"Finished":    {"400", "620"},
+				"Up \"Next\"": {"730"},
+				"favorite":    {"400"},
+				"Playing":     {"70"},
+			},
+		},
+		{
+			name:        "no collection files",
+			installPath: "testdata/empty",
+			steamID:     "12345",
+			want:        map[string][]string{},
+		},
+		{
+			name:        "invalid collection value",
+			installPath: "testdata/broken-collections",
+			steamID:     "12345",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown account",
+			installPath: "testdata/steam",
+			steamID:     "99999",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid steam id",
+			installPath: "testdata/steam",
+			steamID:     "not-an-id",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadLocalCollections(tt.installPath, tt.steamID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ReadLocalCollections() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadLocalCollections() error = %s", err.Error())
+			}
+			for _, appIDs := range got {
+				sort.Strings(appIDs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadLocalCollections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
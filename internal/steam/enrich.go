@@ -0,0 +1,125 @@
+package steam
+
+import (
+	"fmt"
+	"kanbanchan/pkg/steam"
+	"strings"
+	"sync"
+)
+
+const appDetailWorkers = 4
+
+// AppError records a Steam app that couldn't be retrieved or parsed
+type AppError struct {
+	AppID string
+	Err   error
+}
+
+func (e AppError) Error() string {
+	if e.AppID == "" {
+		return e.Err.Error() // not about a single app, like an unreadable library
+	}
+	return fmt.Sprintf("app id %s: %s", e.AppID, e.Err.Error())
+}
+
+// PartialError is returned alongside results when some apps failed to load.
+// Every other app is still present in the results
+type PartialError struct {
+	Failed []AppError
+}
+
+func (e *PartialError) Error() string {
+	var failures []string
+	for _, failed := range e.Failed {
+		failures = append(failures, failed.Error())
+	}
+	return fmt.Sprintf("failed to retrieve %d app(s): %s", len(e.Failed), strings.Join(failures, "; "))
+}
+
+// newPartialError returns a *PartialError for failed, or nil when nothing failed
+func newPartialError(failed []AppError) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return &PartialError{Failed: failed}
+}
+
+// fetchApps retrieves app details for every app ID. Requests share the Steam
+// client's rate limiter, so adding workers only helps until the store's
+// limit is reached
+func (sc *SteamClient) fetchApps(appIDs []string) (map[string]*steam.SteamApp, []AppError) {
+	var mu sync.Mutex
+	apps := make(map[string]*steam.SteamApp)
+	failed := forEachApp(appIDs, func(appID string) error {
+		steamApp, err := sc.steam.GetApp(appID)
+		if err != nil {
+			return err
+		}
+		if !steamApp.Success {
+			return fmt.Errorf("steam returned no details")
+		}
+		mu.Lock()
+		apps[appID] = steamApp
+		mu.Unlock()
+		return nil
+	})
+	return apps, failed
+}
+
+// fetchCompletions retrieves achievement completion for every app ID. Apps
+// without achievements are left out of the results
+func (sc *SteamClient) fetchCompletions(appIDs []string) (map[string]*Completion, []AppError) {
+	var mu sync.Mutex
+	completions := make(map[string]*Completion)
+	failed := forEachApp(appIDs, func(appID string) error {
+		completion, err := sc.GetCompletion(appID)
+		if err != nil {
+			return err
+		}
+		if completion != nil {
+			mu.Lock()
+			completions[appID] = completion
+			mu.Unlock()
+		}
+		return nil
+	})
+	return completions, failed
+}
+
+// forEachApp calls fn for every app ID using a bounded pool of workers and
+// collects the apps it failed for
+func forEachApp(appIDs []string, fn func(appID string) error) []AppError {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed []AppError
+		queue  = make(chan string)
+	)
+
+	workers := appDetailWorkers
+	if len(appIDs) < workers {
+		workers = len(appIDs)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for appID := range queue {
+				err := fn(appID)
+				if err != nil {
+					mu.Lock()
+					failed = append(failed, AppError{AppID: appID, Err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, appID := range appIDs {
+		queue <- appID
+	}
+	close(queue)
+	wg.Wait()
+
+	return failed
+}
@@ -0,0 +1,220 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"kanbanchan/pkg/vdf"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stateFullyInstalled is the appmanifest StateFlags bit set once a game has
+// finished installing
+const stateFullyInstalled = 4
+
+// InstalledGame contains info about a game installed by the local Steam client
+type InstalledGame struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	LibraryPath string    `json:"libraryPath"`
+	InstallDir  string    `json:"installDir"`
+	SizeOnDisk  int64     `json:"sizeOnDisk"`
+	BuildID     string    `json:"buildID"`
+	LastUpdated time.Time `json:"lastUpdated,omitempty"`
+}
+
+// appManifest mirrors the fields read from an appmanifest_*.acf file
+type appManifest struct {
+	AppState struct {
+		AppID       string `vdf:"appid"`
+		Name        string `vdf:"name"`
+		InstallDir  string `vdf:"installdir"`
+		StateFlags  int    `vdf:"StateFlags"`
+		SizeOnDisk  int64  `vdf:"SizeOnDisk"`
+		BuildID     string `vdf:"buildid"`
+		LastUpdated int64  `vdf:"LastUpdated"`
+	} `vdf:"AppState"`
+}
+
+// InstalledGames scans every Steam library folder for installed games,
+// keyed by app ID. Games that are still downloading are left out. Manifests
+// that can't be read are returned along with the remaining games in a
+// *PartialError, as is a libraryfolders.vdf that can't be read, in which
+// case only the install path is scanned
+func (sc *SteamClient) InstalledGames() (map[string]InstalledGame, error) {
+	if sc.installPath == "" {
+		return nil, fmt.Errorf("steam client install path not found")
+	}
+
+	var failed []AppError
+	libraries, err := libraryFolders(sc.installPath)
+	if err != nil {
+		failed = append(failed, AppError{Err: err})
+	}
+
+	games := make(map[string]InstalledGame)
+	for _, library := range libraries {
+		manifests, err := filepath.Glob(filepath.Join(library, "steamapps", "appmanifest_*.acf"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range manifests {
+			var manifest appManifest
+			fileContent, err := os.ReadFile(path)
+			if err == nil {
+				err = vdf.Unmarshal(fileContent, &manifest)
+			}
+			if err != nil {
+				failed = append(failed, AppError{
+					AppID: manifestAppID(path),
+					Err:   fmt.Errorf("failed to read app manifest %s: %s", path, err.Error()),
+				})
+				continue
+			}
+
+			state := manifest.AppState
+			if state.StateFlags&stateFullyInstalled == 0 {
+				continue
+			}
+			game := InstalledGame{
+				ID:          state.AppID,
+				Name:        state.Name,
+				LibraryPath: library,
+				InstallDir:  filepath.Join(library, "steamapps", "common", state.InstallDir),
+				SizeOnDisk:  state.SizeOnDisk,
+				BuildID:     state.BuildID,
+			}
+			if state.LastUpdated > 0 {
+				game.LastUpdated = time.Unix(state.LastUpdated, 0)
+			}
+			games[game.ID] = game
+		}
+	}
+
+	return games, newPartialError(failed)
+}
+
+// installState is what the local Steam client has installed
+type installState struct {
+	known      bool                     // false when a game missing from installed may still be installed
+	installed  map[string]InstalledGame // keyed by app ID
+	unreadable map[string]bool          // app IDs whose manifest can't be read
+}
+
+// readInstallState reads the games installed by the local Steam client.
+// Install state is only known when the client is on this machine and every
+// library was scanned, so an unreadable libraryfolders.vdf leaves it unknown
+// for the whole run rather than marking games in other libraries uninstalled.
+// Games whose manifest can't be read are returned as unreadable so they're
+// skipped rather than synced as not installed
+func (sc *SteamClient) readInstallState() (installState, []AppError, error) {
+	state := installState{unreadable: make(map[string]bool)}
+	if sc.installPath == "" {
+		return state, nil, nil
+	}
+
+	var failed []AppError
+	var partial *PartialError
+	installed, err := sc.InstalledGames()
+	if errors.As(err, &partial) {
+		failed = partial.Failed
+	} else if err != nil {
+		return state, nil, fmt.Errorf("failed to get installed games: %s", err.Error())
+	}
+
+	state.known = true
+	state.installed = installed
+	for _, appErr := range failed {
+		if appErr.AppID == "" {
+			state.known = false
+			continue
+		}
+		state.unreadable[appErr.AppID] = true
+	}
+	return state, failed, nil
+}
+
+// libraryFolders returns every Steam library root listed in libraryfolders.vdf,
+// always including the install path itself. Libraries reached through
+// symlinks are only listed once. When libraryfolders.vdf can't be read, the
+// install path is returned along with the error
+func libraryFolders(installPath string) ([]string, error) {
+	libraries := []string{installPath}
+
+	path := filepath.Join(installPath, "steamapps", "libraryfolders.vdf")
+	fileContent, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return libraries, nil
+	} else if err != nil {
+		return libraries, fmt.Errorf("failed to read library folders from %s: %s", path, err.Error())
+	}
+	root, err := vdf.Parse(fileContent)
+	if err != nil {
+		return libraries, fmt.Errorf("failed to read library folders from %s: %s", path, err.Error())
+	}
+
+	// Libraries are visited by number so the first path seen for a folder,
+	// rather than whichever map order comes up, is the one kept
+	sections := vdf.Lookup(root, "libraryfolders")
+	numbers := []int{}
+	for key := range sections {
+		if number, err := strconv.Atoi(key); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	// Current clients store a section per library with a "path" key, while
+	// older ones map the library number straight to its path
+	for _, number := range numbers {
+		var library string
+		switch folder := sections[strconv.Itoa(number)].(type) {
+		case map[string]interface{}:
+			library = vdf.String(folder, "path")
+		case string:
+			library = folder
+		}
+		if library == "" || containsDir(libraries, library) {
+			continue
+		}
+		libraries = append(libraries, filepath.Clean(library))
+	}
+
+	return libraries, nil
+}
+
+// manifestAppID returns the app ID in the name of an appmanifest_*.acf file
+func manifestAppID(path string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "appmanifest_"), ".acf")
+}
+
+// containsDir reports whether dirs has a directory that is the same as dir
+// once symlinks are resolved
+func containsDir(dirs []string, dir string) bool {
+	for _, existing := range dirs {
+		if sameDir(existing, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameDir reports whether two paths are the same directory, like
+// ~/.steam/steam and the ~/.local/share/Steam it links to
+func sameDir(a string, b string) bool {
+	return resolveDir(a) == resolveDir(b)
+}
+
+// resolveDir returns a path with symlinks resolved, or cleaned when it can't
+// be resolved
+func resolveDir(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return resolved
+}
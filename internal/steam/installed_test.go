@@ -0,0 +1,177 @@
+package steam
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestInstalledGames(t *testing.T) {
+	tests := []struct {
+		name        string
+		installPath string
+		want        map[string]InstalledGame
+		wantFailed  []string // app IDs in the *PartialError, "" for the library folders
+	}{
+		{
+			name:        "every library",
+			installPath: "testdata/steam",
+			want: map[string]InstalledGame{
+				"620": {
+					ID:          "620",
+					Name:        "Portal 2",
+					LibraryPath: "testdata/steam",
+					InstallDir:  filepath.Join("testdata/steam", "steamapps", "common", "Portal 2"),
+					SizeOnDisk:  12884901888,
+					BuildID:     "10247512",
+					LastUpdated: time.Unix(1700000000, 0),
+				},
+				"1145360": {
+					ID:          "1145360",
+					Name:        "Hades",
+					LibraryPath: "testdata/library",
+					InstallDir:  filepath.Join("testdata/library", "steamapps", "common", "Hades"),
+					SizeOnDisk:  15316223434,
+					BuildID:     "6512315",
+				},
+			},
+			wantFailed: []string{"999"},
+		},
+		{
+			name:        "unreadable library folders",
+			installPath: "testdata/broken-libraries",
+			want: map[string]InstalledGame{
+				"620": {
+					ID:          "620",
+					Name:        "Portal 2",
+					LibraryPath: "testdata/broken-libraries",
+					InstallDir:  filepath.Join("testdata/broken-libraries", "steamapps", "common", "Portal 2"),
+					SizeOnDisk:  12884901888,
+					BuildID:     "10247512",
+					LastUpdated: time.Unix(1700000000, 0),
+				},
+			},
+			wantFailed: []string{""},
+		},
+		{
+			name:        "no library folders",
+			installPath: "testdata/library",
+			want: map[string]InstalledGame{
+				"1145360": {
+					ID:          "1145360",
+					Name:        "Hades",
+					LibraryPath: "testdata/library",
+					InstallDir:  filepath.Join("testdata/library", "steamapps", "common", "Hades"),
+					SizeOnDisk:  15316223434,
+					BuildID:     "6512315",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SteamClient{installPath: tt.installPath}
+			got, err := sc.InstalledGames()
+
+			var failed []string
+			var partial *PartialError
+			if errors.As(err, &partial) {
+				for _, appErr := range partial.Failed {
+					failed = append(failed, appErr.AppID)
+				}
+			} else if err != nil {
+				t.Fatalf("InstalledGames() error = %s", err.Error())
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("InstalledGames() failed = %v, want %v", failed, tt.wantFailed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InstalledGames() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadInstallState(t *testing.T) {
+	tests := []struct {
+		name           string
+		installPath    string
+		wantKnown      bool
+		wantInstalled  []string
+		wantUnreadable []string
+	}{
+		{name: "no steam client", installPath: "", wantKnown: false},
+		{name: "every library scanned", installPath: "testdata/steam", wantKnown: true, wantInstalled: []string{"1145360", "620"}, wantUnreadable: []string{"999"}},
+		{name: "missing library folders", installPath: "testdata/library", wantKnown: true, wantInstalled: []string{"1145360"}},
+		{name: "corrupt library folders", installPath: "testdata/broken-libraries", wantKnown: false, wantInstalled: []string{"620"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SteamClient{installPath: tt.installPath}
+			state, _, err := sc.readInstallState()
+			if err != nil {
+				t.Fatalf("readInstallState() error = %s", err.Error())
+			}
+			if state.known != tt.wantKnown {
+				t.Errorf("readInstallState() known = %t, want %t", state.known, tt.wantKnown)
+			}
+			var installed, unreadable []string
+			for appID := range state.installed {
+				installed = append(installed, appID)
+			}
+			for appID := range state.unreadable {
+				unreadable = append(unreadable, appID)
+			}
+			sort.Strings(installed)
+			sort.Strings(unreadable)
+			if !reflect.DeepEqual(installed, tt.wantInstalled) {
+				t.Errorf("readInstallState() installed = %v, want %v", installed, tt.wantInstalled)
+			}
+			if !reflect.DeepEqual(unreadable, tt.wantUnreadable) {
+				t.Errorf("readInstallState() unreadable = %v, want %v", unreadable, tt.wantUnreadable)
+			}
+		})
+	}
+}
+
+func TestLibraryFoldersSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	install := filepath.Join(dir, "share", "Steam")
+	library := filepath.Join(dir, "games")
+	for _, path := range []string{filepath.Join(install, "steamapps"), library} {
+		err := os.MkdirAll(path, 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	installLink := filepath.Join(dir, "steam")
+	libraryLink := filepath.Join(dir, "games-link")
+	for link, target := range map[string]string{installLink: install, libraryLink: library} {
+		err := os.Symlink(target, link)
+		if err != nil {
+			t.Skipf("symlinks aren't supported: %s", err.Error())
+		}
+	}
+	folders := `"libraryfolders" { "0" { "path" "` + install + `" } "1" { "path" "` + library + `" } "2" { "path" "` + libraryLink + `" } }`
+	err := os.WriteFile(filepath.Join(install, "steamapps", "libraryfolders.vdf"), []byte(folders), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := libraryFolders(installLink)
+	if err != nil {
+		t.Fatalf("libraryFolders() error = %s", err.Error())
+	}
+	sort.Strings(got)
+	want := []string{library, installLink}
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("libraryFolders() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package steam
+
+import (
+	"fmt"
+	"kanbanchan/pkg/vdf"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Shortcut is a non-Steam game added to the Steam library
+type Shortcut struct {
+	AppID      string    `json:"appid"`
+	Name       string    `json:"name"`
+	Exe        string    `json:"exe"`
+	StartDir   string    `json:"startDir"`
+	LastPlayed time.Time `json:"lastPlayed,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+}
+
+// LocalPlaytime is the playtime the local Steam client has recorded for a game
+type LocalPlaytime struct {
+	AppID          string        `json:"appid"`
+	LastPlayed     time.Time     `json:"lastPlayed,omitempty"`
+	Playtime       time.Duration `json:"playtime"`
+	RecentPlaytime time.Duration `json:"recentPlaytime"`
+}
+
+// GetShortcuts reads the non-Steam games the user has added to their library
+// from the local Steam client
+func (sc *SteamClient) GetShortcuts() ([]Shortcut, error) {
+	userDir, err := sc.localUserDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(userDir, "config", "shortcuts.vdf")
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	root, err := vdf.DecodeBinary(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shortcuts from %s: %s", path, err.Error())
+	}
+
+	var shortcuts []Shortcut
+	for _, value := range indexedValues(vdf.Lookup(root, "shortcuts")) {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		shortcut := Shortcut{
+			AppID:    shortcutAppID(entry),
+			Name:     vdf.String(entry, "AppName"),
+			Exe:      vdf.String(entry, "Exe"),
+			StartDir: vdf.String(entry, "StartDir"),
+		}
+		if lastPlayed, err := strconv.ParseInt(vdf.String(entry, "LastPlayTime"), 10, 64); err == nil && lastPlayed > 0 {
+			shortcut.LastPlayed = time.Unix(lastPlayed, 0)
+		}
+		for _, tag := range indexedValues(vdf.Lookup(entry, "tags")) {
+			if name, ok := tag.(string); ok {
+				shortcut.Tags = append(shortcut.Tags, name)
+			}
+		}
+		shortcuts = append(shortcuts, shortcut)
+	}
+
+	return shortcuts, nil
+}
+
+// GetLocalPlaytime reads the playtime recorded by the local Steam client for
+// each game, keyed by app ID. This includes offline sessions the Web API may
+// not have seen yet. A user who has never launched the client has no playtime
+func (sc *SteamClient) GetLocalPlaytime() (map[string]LocalPlaytime, error) {
+	userDir, err := sc.localUserDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(userDir, "config", "localconfig.vdf")
+	fileContent, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]LocalPlaytime{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	root, err := vdf.Parse(fileContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playtime from %s: %s", path, err.Error())
+	}
+
+	playtimes := make(map[string]LocalPlaytime)
+	apps := vdf.Lookup(root, "UserLocalConfigStore", "Software", "Valve", "Steam", "apps")
+	for appID, value := range apps {
+		app, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		playtime := LocalPlaytime{
+			AppID:          appID,
+			Playtime:       minutesValue(vdf.String(app, "Playtime")),
+			RecentPlaytime: minutesValue(vdf.String(app, "Playtime2wks")),
+		}
+		if lastPlayed, err := strconv.ParseInt(vdf.String(app, "LastPlayed"), 10, 64); err == nil && lastPlayed > 0 {
+			playtime.LastPlayed = time.Unix(lastPlayed, 0)
+		}
+		if playtime.Playtime == 0 && playtime.LastPlayed.IsZero() {
+			continue
+		}
+		playtimes[appID] = playtime
+	}
+
+	return playtimes, nil
+}
+
+// localUserDir returns the local Steam client's userdata directory for the authenticated user
+func (sc *SteamClient) localUserDir() (string, error) {
+	if sc.installPath == "" {
+		return "", fmt.Errorf("steam client install path not found")
+	}
+	return userDataDir(sc.installPath, sc.steamID)
+}
+
+// shortcutAppID returns the app ID Steam uses for a shortcut, which is stored
+// as a signed 32-bit integer but displayed unsigned
+func shortcutAppID(entry map[string]interface{}) string {
+	for key, value := range entry {
+		if key != "appid" && key != "AppId" && key != "appId" {
+			continue
+		}
+		if id, ok := value.(int32); ok {
+			return strconv.FormatUint(uint64(uint32(id)), 10)
+		}
+	}
+	return ""
+}
+
+// indexedValues returns the values of a section keyed by list index, such as
+// shortcuts or tags, in index order
+func indexedValues(section map[string]interface{}) []interface{} {
+	indexes := []int{}
+	for key := range section {
+		if index, err := strconv.Atoi(key); err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	values := make([]interface{}, 0, len(indexes))
+	for _, index := range indexes {
+		values = append(values, section[strconv.Itoa(index)])
+	}
+	return values
+}
+
+// minutesValue parses a number of minutes, treating anything invalid as zero
+func minutesValue(minutes string) time.Duration {
+	n, err := strconv.ParseInt(minutes, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n) * time.Minute
+}
@@ -0,0 +1,106 @@
+package steam
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetShortcuts(t *testing.T) {
+	tests := []struct {
+		name        string
+		installPath string
+		want        []Shortcut
+		wantErr     bool
+	}{
+		{
+			name:        "shortcuts",
+			installPath: "testdata/steam",
+			want: []Shortcut{
+				{
+					AppID:      "3060399406",
+					Name:       "Celeste \"Classic\"",
+					Exe:        "\"/usr/bin/celeste\"",
+					StartDir:   "/usr/bin/",
+					LastPlayed: time.Unix(1700000000, 0),
+					Tags:       []string{"Platformer", "Favorites"},
+				},
+				{
+					AppID: "42",
+					Name:  "Émulateur",
+				},
+			},
+		},
+		{
+			name:        "no shortcuts file",
+			installPath: "testdata/empty",
+		},
+		{
+			name:        "truncated shortcuts file",
+			installPath: "testdata/broken-local",
+			wantErr:     true,
+		},
+		{
+			name:    "no install path",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SteamClient{installPath: tt.installPath, steamID: "12345"}
+			got, err := sc.GetShortcuts()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetShortcuts() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetShortcuts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLocalPlaytime(t *testing.T) {
+	tests := []struct {
+		name        string
+		installPath string
+		want        map[string]LocalPlaytime
+		wantErr     bool
+	}{
+		{
+			name:        "played and unplayed games",
+			installPath: "testdata/steam",
+			want: map[string]LocalPlaytime{
+				"400": {
+					AppID:          "400",
+					LastPlayed:     time.Unix(1700000000, 0),
+					Playtime:       125 * time.Minute,
+					RecentPlaytime: 30 * time.Minute,
+				},
+			},
+		},
+		{
+			name:        "no localconfig file",
+			installPath: "testdata/empty",
+			want:        map[string]LocalPlaytime{},
+		},
+		{
+			name:        "unterminated localconfig file",
+			installPath: "testdata/broken-local",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SteamClient{installPath: tt.installPath, steamID: "12345"}
+			got, err := sc.GetLocalPlaytime()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetLocalPlaytime() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetLocalPlaytime() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"kanbanchan/pkg/steam"
+	"time"
+)
+
+// Playtime is how long the authenticated user has played a game, in total and
+// on each platform. Platform playtimes only include time Steam saw while
+// online, so they can add up to less than Total
+type Playtime struct {
+	Total        time.Duration `json:"total"`
+	Windows      time.Duration `json:"windows"`
+	Mac          time.Duration `json:"mac"`
+	Linux        time.Duration `json:"linux"`
+	Deck         time.Duration `json:"deck"`
+	Disconnected time.Duration `json:"disconnected"`     // played while offline
+	Recent       time.Duration `json:"recent,omitempty"` // in the last two weeks
+}
+
+// newPlaytime reads the playtimes of an owned game, which the Web API reports
+// in minutes
+func newPlaytime(app steam.OwnedApp) (Playtime, error) {
+	var playtime Playtime
+	var err error
+	minutes := []struct {
+		value json.Number
+		into  *time.Duration
+	}{
+		{app.Playtime, &playtime.Total},
+		{app.PlaytimeWindows, &playtime.Windows},
+		{app.PlaytimeMac, &playtime.Mac},
+		{app.PlaytimeLinux, &playtime.Linux},
+		{app.PlaytimeDeck, &playtime.Deck},
+		{app.PlaytimeDisconnected, &playtime.Disconnected},
+	}
+	for _, m := range minutes {
+		*m.into, err = minutesDuration(m.value)
+		if err != nil {
+			return Playtime{}, err
+		}
+	}
+	return playtime, nil
+}
+
+// minutesDuration converts a number of minutes from the Web API to a
+// duration. Missing values are no time at all
+func minutesDuration(minutes json.Number) (time.Duration, error) {
+	if minutes == "" {
+		return 0, nil
+	}
+	n, err := minutes.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse playtime %s: %s", minutes, err.Error())
+	}
+	return time.Duration(n) * time.Minute, nil
+}
+
+// lastPlayedTime converts a Unix timestamp from the Web API to a time, which
+// is zero when the game has never been played
+func lastPlayedTime(timestamp json.Number) (time.Time, error) {
+	if timestamp == "" {
+		return time.Time{}, nil
+	}
+	seconds, err := timestamp.Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse last played time %s: %s", timestamp, err.Error())
+	}
+	if seconds <= 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(seconds, 0), nil
+}
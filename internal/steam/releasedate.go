@@ -0,0 +1,237 @@
+package steam
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DatePrecision is how much of a release date the store has announced
+type DatePrecision string
+
+const (
+	PrecisionDay     DatePrecision = "day"
+	PrecisionMonth   DatePrecision = "month"
+	PrecisionQuarter DatePrecision = "quarter"
+	PrecisionSeason  DatePrecision = "season"
+	PrecisionYear    DatePrecision = "year"
+	PrecisionUnknown DatePrecision = "unknown"
+)
+
+// ReleaseDate is a release date as precise as the store has announced it.
+// Start and End are the first and last days of the window the game is due in,
+// as midnight UTC, and are the same day for exact dates. Both are zero when
+// the date is unknown
+type ReleaseDate struct {
+	Start     time.Time     `json:"start"`
+	End       time.Time     `json:"end"`
+	Precision DatePrecision `json:"precision"`
+	Text      string        `json:"text,omitempty"` // as the store shows it
+}
+
+// unknownReleaseDates are store texts for games without an announced date
+var unknownReleaseDates = map[string]bool{
+	"":                true,
+	"coming soon":     true,
+	"to be announced": true,
+	"tba":             true,
+	"tbd":             true,
+	"demnächst":       true,
+	"bald verfügbar":  true,
+	"prochainement":   true,
+	"à venir":         true,
+	"próximamente":    true,
+	"em breve":        true,
+	"prossimamente":   true,
+	"即将推出":            true,
+	"即將推出":            true,
+	"近日登場":            true,
+	"近日公開":            true,
+}
+
+// monthNames maps month names and abbreviations in the store's languages,
+// lowercased and without trailing periods, to months
+var monthNames = map[string]time.Month{
+	"jan": time.January, "january": time.January, "januar": time.January, "janv": time.January,
+	"janvier": time.January, "ene": time.January, "enero": time.January, "gen": time.January,
+	"gennaio": time.January, "janeiro": time.January,
+	"feb": time.February, "february": time.February, "februar": time.February, "févr": time.February,
+	"février": time.February, "febrero": time.February, "febbraio": time.February, "fev": time.February,
+	"fevereiro": time.February,
+	"mar":       time.March, "march": time.March, "mär": time.March, "märz": time.March, "mars": time.March,
+	"marzo": time.March, "março": time.March,
+	"apr": time.April, "april": time.April, "avr": time.April, "avril": time.April, "abr": time.April,
+	"abril": time.April, "aprile": time.April,
+	"may": time.May, "mai": time.May, "mayo": time.May, "mag": time.May, "maggio": time.May,
+	"maio": time.May,
+	"jun":  time.June, "june": time.June, "juni": time.June, "juin": time.June, "junio": time.June,
+	"giu": time.June, "giugno": time.June, "junho": time.June,
+	"jul": time.July, "july": time.July, "juli": time.July, "juil": time.July, "juillet": time.July,
+	"julio": time.July, "lug": time.July, "luglio": time.July, "julho": time.July,
+	"aug": time.August, "august": time.August, "août": time.August, "ago": time.August,
+	"agosto": time.August,
+	"sep":    time.September, "sept": time.September, "september": time.September,
+	"septembre": time.September, "septiembre": time.September, "set": time.September,
+	"settembre": time.September, "setembro": time.September,
+	"oct": time.October, "october": time.October, "okt": time.October, "oktober": time.October,
+	"octobre": time.October, "octubre": time.October, "ott": time.October, "ottobre": time.October,
+	"out": time.October, "outubro": time.October,
+	"nov": time.November, "november": time.November, "novembre": time.November,
+	"noviembre": time.November, "novembro": time.November,
+	"dec": time.December, "december": time.December, "dez": time.December, "dezember": time.December,
+	"déc": time.December, "décembre": time.December, "dic": time.December, "diciembre": time.December,
+	"dicembre": time.December, "dezembro": time.December,
+}
+
+// seasonNames maps season names in the store's languages to the month their
+// window starts in. Winter runs from December into the next year
+var seasonNames = map[string]time.Month{
+	"spring": time.March, "frühling": time.March, "printemps": time.March, "primavera": time.March,
+	"summer": time.June, "sommer": time.June, "été": time.June, "verano": time.June,
+	"estate": time.June, "verão": time.June,
+	"fall": time.September, "autumn": time.September, "herbst": time.September,
+	"automne": time.September, "otoño": time.September, "autunno": time.September,
+	"outono": time.September,
+	"winter": time.December, "hiver": time.December, "invierno": time.December,
+	"inverno": time.December,
+}
+
+// seasonLabels are the English names used for season windows
+var seasonLabels = map[time.Month]string{
+	time.March:     "Spring",
+	time.June:      "Summer",
+	time.September: "Fall",
+	time.December:  "Winter",
+}
+
+// ParseReleaseDate parses a release date as the store shows it, such as
+// "Oct 16, 2025", "16 Oct, 2025", "16. Okt. 2025", "2025年10月16日",
+// "October 2025", "Q3 2025", "Summer 2025", "2025" or "Coming soon". Texts
+// for unannounced dates, and free-form texts like "Early 2026" or "When it's
+// done", parse to PrecisionUnknown with the store's text kept, so the game
+// is still synced with that text as its release window
+func ParseReleaseDate(text string) ReleaseDate {
+	release, err := parseReleaseDate(text)
+	if err != nil {
+		return ReleaseDate{Precision: PrecisionUnknown, Text: strings.TrimSpace(text)}
+	}
+	return release
+}
+
+// parseReleaseDate parses a release date as the store shows it, returning an
+// error for texts that aren't a date it understands
+func parseReleaseDate(text string) (ReleaseDate, error) {
+	text = strings.TrimSpace(text)
+	if unknownReleaseDates[strings.ToLower(text)] {
+		return ReleaseDate{Precision: PrecisionUnknown, Text: text}, nil
+	}
+
+	var year, day, quarter int
+	var month, season time.Month
+	for _, token := range releaseDateTokens(text) {
+		if n, err := strconv.Atoi(token); err == nil {
+			switch {
+			case len(token) == 4 && year == 0:
+				year = n
+			case n >= 1 && n <= 31 && day == 0 && month == 0 && year == 0,
+				n >= 1 && n <= 31 && day == 0 && month != 0:
+				day = n
+			case n >= 1 && n <= 12 && month == 0 && year != 0:
+				month = time.Month(n) // numeric months, as in 2025年10月
+			default:
+				return ReleaseDate{}, fmt.Errorf("failed to parse release date \"%s\": unexpected number %s", text, token)
+			}
+			continue
+		}
+		if m, ok := monthNames[token]; ok && month == 0 {
+			month = m
+		} else if s, ok := seasonNames[token]; ok && season == 0 {
+			season = s
+		} else if len(token) == 2 && token[0] == 'q' && token[1] >= '1' && token[1] <= '4' && quarter == 0 {
+			quarter = int(token[1] - '0')
+		} else if token != "de" && token != "del" && token != "of" { // "16 de octubre de 2025"
+			return ReleaseDate{}, fmt.Errorf("failed to parse release date \"%s\": unexpected word %s", text, token)
+		}
+	}
+	if year == 0 {
+		return ReleaseDate{}, fmt.Errorf("failed to parse release date \"%s\": no year", text)
+	}
+
+	release := ReleaseDate{Text: text}
+	switch {
+	case month != 0 && day != 0 && quarter == 0 && season == 0:
+		release.Precision = PrecisionDay
+		release.Start = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		if release.Start.Month() != month {
+			return ReleaseDate{}, fmt.Errorf("failed to parse release date \"%s\": no such day", text)
+		}
+		release.End = release.Start
+	case month != 0 && day == 0 && quarter == 0 && season == 0:
+		release.Precision = PrecisionMonth
+		release.Start = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		release.End = release.Start.AddDate(0, 1, -1)
+	case quarter != 0 && month == 0 && day == 0 && season == 0:
+		release.Precision = PrecisionQuarter
+		release.Start = time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC)
+		release.End = release.Start.AddDate(0, 3, -1)
+	case season != 0 && month == 0 && day == 0 && quarter == 0:
+		release.Precision = PrecisionSeason
+		release.Start = time.Date(year, season, 1, 0, 0, 0, 0, time.UTC)
+		release.End = release.Start.AddDate(0, 3, -1)
+	case month == 0 && day == 0 && quarter == 0 && season == 0:
+		release.Precision = PrecisionYear
+		release.Start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		release.End = time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	default:
+		return ReleaseDate{}, fmt.Errorf("failed to parse release date \"%s\"", text)
+	}
+	return release, nil
+}
+
+// releaseDateTokens lowercases text and splits it into words and numbers,
+// dropping punctuation and the CJK year, month and day markers
+func releaseDateTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '.' || r == '/' || r == '-' ||
+			r == '年' || r == '月' || r == '日' || r == '년' || r == '월' || r == '일'
+	})
+}
+
+// Known reports whether the store has announced at least a year
+func (rd ReleaseDate) Known() bool {
+	return rd.Precision != "" && rd.Precision != PrecisionUnknown && !rd.Start.IsZero()
+}
+
+// Exact reports whether the store has announced the day
+func (rd ReleaseDate) Exact() bool {
+	return rd.Known() && rd.Precision == PrecisionDay
+}
+
+// Window describes when the game is due in English, like "Oct 16, 2025",
+// "October 2025", "Q3 2025", "Summer 2025" or "2025". Unknown dates use the
+// store's text, or "TBA" when it has none
+func (rd ReleaseDate) Window() string {
+	if !rd.Known() {
+		if rd.Text == "" {
+			return "TBA"
+		}
+		return rd.Text
+	}
+	switch rd.Precision {
+	case PrecisionDay:
+		return rd.Start.Format("Jan 2, 2006")
+	case PrecisionMonth:
+		return rd.Start.Format("January 2006")
+	case PrecisionQuarter:
+		return fmt.Sprintf("Q%d %d", (int(rd.Start.Month())+2)/3, rd.Start.Year())
+	case PrecisionSeason:
+		return fmt.Sprintf("%s %d", seasonLabels[rd.Start.Month()], rd.Start.Year())
+	}
+	return strconv.Itoa(rd.Start.Year())
+}
+
+func (rd ReleaseDate) String() string {
+	return rd.Window()
+}
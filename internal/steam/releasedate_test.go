@@ -0,0 +1,170 @@
+package steam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReleaseDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name      string
+		text      string
+		precision DatePrecision
+		start     time.Time
+		end       time.Time
+		window    string
+	}{
+		{
+			name:      "english day",
+			text:      "Oct 16, 2025",
+			precision: PrecisionDay,
+			start:     day(2025, time.October, 16),
+			end:       day(2025, time.October, 16),
+			window:    "Oct 16, 2025",
+		},
+		{
+			name:      "day before month",
+			text:      "16 Oct, 2025",
+			precision: PrecisionDay,
+			start:     day(2025, time.October, 16),
+			end:       day(2025, time.October, 16),
+			window:    "Oct 16, 2025",
+		},
+		{
+			name:      "german day",
+			text:      "16. Okt. 2025",
+			precision: PrecisionDay,
+			start:     day(2025, time.October, 16),
+			end:       day(2025, time.October, 16),
+			window:    "Oct 16, 2025",
+		},
+		{
+			name:      "spanish day",
+			text:      "16 de octubre de 2025",
+			precision: PrecisionDay,
+			start:     day(2025, time.October, 16),
+			end:       day(2025, time.October, 16),
+			window:    "Oct 16, 2025",
+		},
+		{
+			name:      "chinese day",
+			text:      "2025年10月16日",
+			precision: PrecisionDay,
+			start:     day(2025, time.October, 16),
+			end:       day(2025, time.October, 16),
+			window:    "Oct 16, 2025",
+		},
+		{
+			name:      "leap day",
+			text:      "Feb 29, 2028",
+			precision: PrecisionDay,
+			start:     day(2028, time.February, 29),
+			end:       day(2028, time.February, 29),
+			window:    "Feb 29, 2028",
+		},
+		{
+			name:      "month",
+			text:      "October 2025",
+			precision: PrecisionMonth,
+			start:     day(2025, time.October, 1),
+			end:       day(2025, time.October, 31),
+			window:    "October 2025",
+		},
+		{
+			name:      "numeric month",
+			text:      "2026年2月",
+			precision: PrecisionMonth,
+			start:     day(2026, time.February, 1),
+			end:       day(2026, time.February, 28),
+			window:    "February 2026",
+		},
+		{
+			name:      "quarter",
+			text:      "Q3 2025",
+			precision: PrecisionQuarter,
+			start:     day(2025, time.July, 1),
+			end:       day(2025, time.September, 30),
+			window:    "Q3 2025",
+		},
+		{
+			name:      "season",
+			text:      "Summer 2025",
+			precision: PrecisionSeason,
+			start:     day(2025, time.June, 1),
+			end:       day(2025, time.August, 31),
+			window:    "Summer 2025",
+		},
+		{
+			name:      "winter runs into the next year",
+			text:      "Winter 2025",
+			precision: PrecisionSeason,
+			start:     day(2025, time.December, 1),
+			end:       day(2026, time.February, 28),
+			window:    "Winter 2025",
+		},
+		{
+			name:      "year",
+			text:      "2025",
+			precision: PrecisionYear,
+			start:     day(2025, time.January, 1),
+			end:       day(2025, time.December, 31),
+			window:    "2025",
+		},
+		{
+			name:      "coming soon",
+			text:      "Coming soon",
+			precision: PrecisionUnknown,
+			window:    "Coming soon",
+		},
+		{
+			name:      "no text",
+			text:      "",
+			precision: PrecisionUnknown,
+			window:    "TBA",
+		},
+		{
+			name:      "free-form window",
+			text:      " Early 2026 ",
+			precision: PrecisionUnknown,
+			window:    "Early 2026",
+		},
+		{
+			name:      "free-form text",
+			text:      "When it's done",
+			precision: PrecisionUnknown,
+			window:    "When it's done",
+		},
+		{
+			name:      "no such day",
+			text:      "Feb 30, 2025",
+			precision: PrecisionUnknown,
+			window:    "Feb 30, 2025",
+		},
+		{
+			name:      "mixed precisions",
+			text:      "Q3 October 2025",
+			precision: PrecisionUnknown,
+			window:    "Q3 October 2025",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseReleaseDate(tt.text)
+			if got.Precision != tt.precision {
+				t.Errorf("Precision = %s, want %s", got.Precision, tt.precision)
+			}
+			if !got.Start.Equal(tt.start) || !got.End.Equal(tt.end) {
+				t.Errorf("window = %s to %s, want %s to %s", got.Start, got.End, tt.start, tt.end)
+			}
+			if got.Window() != tt.window {
+				t.Errorf("Window() = %q, want %q", got.Window(), tt.window)
+			}
+			if got.Known() != (tt.precision != PrecisionUnknown) {
+				t.Errorf("Known() = %t for %s", got.Known(), tt.precision)
+			}
+		})
+	}
+}
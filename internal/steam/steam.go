@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"kanbanchan/internal/aws"
+	"kanbanchan/internal/clock"
 	"kanbanchan/pkg/steam"
+	"log"
 	"strings"
 	"time"
 )
@@ -15,60 +18,65 @@ const (
 	CollectionUpNext   = "Up Next"
 	CollectionPlaying  = "Playing"
 
-	steamAPIURL         = "https://api.steampowered.com"
-	steamURL            = "https://store.steampowered.com"
-	steamDateFormat     = "Jan 2, 2006"
-	steamDateAltFormat  = "2 Jan, 2006"
-	steamDateYearFormat = "2006"
+	PlatformWindows = "Windows"
+	PlatformMac     = "macOS"
+	PlatformLinux   = "Linux"
+
+	steamAPIURL = "https://api.steampowered.com"
+	steamURL    = "https://store.steampowered.com"
+
+	appListCachePath = "../../local/cache/applist.json"
+	appListCacheTTL  = 24 * time.Hour
+	appCacheDir      = "../../local/cache/appdetails"
 )
 
-// SteamClient contains auth info, a client, and manually tracked Library Collections
+// SteamClient contains auth info, a client, and the Library Collections each app is in
 type SteamClient struct {
-	steam       steam.SteamClient
+	steam       *steam.SteamClient
 	steamKey    string
 	steamID     string
-	collections struct {
-		finished []string
-		upNext   []string
-		playing  []string
+	installPath string
+	collections map[string]map[string]bool // app ID -> collections
+	clock       clock.Clock
+	settings    struct {
+		apiOptions      []steam.ClientOption
+		installPath     string
+		collectionNames map[string]string
 	}
 }
 
+// ClientOption configures optional settings on a SteamClient
+type ClientOption func(*SteamClient)
+
 // SteamGame contains info about a Steam Game
 type SteamGame struct {
 	ID                       string          `json:"id,omitempty"`
 	Name                     string          `json:"name,omitempty"`
 	HeaderImage              string          `json:"header_image,omitempty"`
 	Genres                   []string        `json:"genres,omitempty"`
-	ReleaseDate              time.Time       `json:"releaseDate,omitempty"`
-	Playtime                 time.Time       `json:"playtime_forever,omitempty"`
-	PlaytimeWindows          time.Time       `json:"playtime_windows_forever,omitempty"`
-	PlaytimeMac              time.Time       `json:"playtime_mac_forever,omitempty"`
-	PlaytimeLinux            time.Time       `json:"playtime_linux_forever,omitempty"`
-	PlaytimeDisconnected     time.Time       `json:"playtime_disconnected,omitempty"`
-	LastPlayed               time.Time       `json:"rtime_last_played,omitempty"`
+	Categories               []string        `json:"categories,omitempty"`
+	Developers               []string        `json:"developers,omitempty"`
+	Publishers               []string        `json:"publishers,omitempty"`
+	Platforms                []string        `json:"platforms,omitempty"`
+	ShortDescription         string          `json:"short_description,omitempty"`
+	MetacriticScore          int             `json:"metacritic_score,omitempty"`
+	Price                    string          `json:"price,omitempty"`
+	IsFree                   bool            `json:"is_free,omitempty"`
+	DLCCount                 int             `json:"dlc_count,omitempty"`
+	Screenshots              []string        `json:"screenshots,omitempty"`
+	ComingSoon               bool            `json:"coming_soon,omitempty"`
+	ReleaseDate              ReleaseDate     `json:"releaseDate"`
+	Owned                    bool            `json:"owned,omitempty"`
+	Playtime                 Playtime        `json:"playtime"`
+	LastPlayed               time.Time       `json:"lastPlayed"` // zero when never played
 	HasCommunityVisibleStats bool            `json:"has_community_visible_stats,omitempty"`
+	Achievements             *Completion     `json:"achievements,omitempty"`
+	InstallStateKnown        bool            `json:"install_state_known,omitempty"` // Installed and SizeOnDisk were read from the Steam client
+	Installed                bool            `json:"installed,omitempty"`
+	SizeOnDisk               int64           `json:"size_on_disk,omitempty"`
 	Collections              map[string]bool `json:"collections,omitempty"`
 }
 
-// SteamApp contains info about a Steam App
-type SteamApp struct {
-	Success bool `json:"success"`
-	Data    struct {
-		Type        string      `json:"type"`
-		Name        string      `json:"name"`
-		AppID       json.Number `json:"steam_appid"`
-		HeaderImage string      `json:"header_image"`
-		Genres      []struct {
-			ID          string `json:"id"`
-			Description string `json:"description"`
-		} `json:"genres"`
-		ReleaseDate struct {
-			Date string `json:"date"`
-		} `json:"release_date"`
-	} `json:"data"`
-}
-
 // WishlistApp contains info about an app on a Wishlist
 type WishlistApp struct {
 	Name        string      `json:"name"`
@@ -101,9 +109,10 @@ type Library struct {
 	} `json:"response"`
 }
 
-// NewClient creates an authenticated client and sets up manually tracked
-// library Collections (since those can't be retrieved via API yet)
-func NewClient(ctx context.Context) (*SteamClient, error) {
+// NewClient creates an authenticated client and loads library Collections.
+// Collections are read from the local Steam client when it can be found, and
+// merged with the manually tracked lists in secrets
+func NewClient(ctx context.Context, opts ...ClientOption) (*SteamClient, error) {
 	var client SteamClient
 	var secrets, err = aws.GetSecrets()
 	if err != nil {
@@ -111,71 +120,134 @@ func NewClient(ctx context.Context) (*SteamClient, error) {
 	}
 	client.steamID = secrets.Steam.ID
 	client.steamKey = secrets.Steam.Key
-	steamClient, err := steam.NewClient(context.Background(), client.steamKey)
+	client.collections = make(map[string]map[string]bool)
+	client.clock = clock.New(nil)
+	for _, opt := range opts {
+		opt(&client)
+	}
+
+	appCache, err := steam.NewFileAppCache(appCacheDir)
 	if err != nil {
 		return nil, err
 	}
-	client.steam = *steamClient
+	apiOptions := append([]steam.ClientOption{
+		steam.WithAppListCache(appListCachePath, appListCacheTTL),
+		steam.WithAppCache(appCache),
+		steam.WithCacheErrorHandler(func(err error) {
+			log.Print(err.Error())
+		}),
+	}, client.settings.apiOptions...)
+	steamClient, err := steam.NewClient(ctx, client.steamKey, apiOptions...)
+	if err != nil {
+		return nil, err
+	}
+	client.steam = steamClient
 
-	var finished, upNext, playing []string
 	for _, jnum := range secrets.Steam.Collections.Finished {
-		finished = append(finished, jnum.String())
+		client.addToCollection(jnum.String(), CollectionFinished)
 	}
 	for _, jnum := range secrets.Steam.Collections.UpNext {
-		upNext = append(upNext, jnum.String())
+		client.addToCollection(jnum.String(), CollectionUpNext)
 	}
 	for _, jnum := range secrets.Steam.Collections.Playing {
-		playing = append(playing, jnum.String())
+		client.addToCollection(jnum.String(), CollectionPlaying)
+	}
+
+	// A configured install path must be readable, but a missing auto-detected
+	// one just means collections come from secrets alone
+	client.installPath = client.settings.installPath
+	if client.installPath == "" {
+		client.installPath = defaultInstallPath()
 	}
-	client.collections.finished = finished
-	client.collections.upNext = upNext
-	client.collections.playing = playing
+	if client.installPath != "" {
+		localCollections, err := ReadLocalCollections(client.installPath, client.steamID)
+		if err != nil && client.settings.installPath != "" {
+			return nil, fmt.Errorf("failed to read steam collections: %s", err.Error())
+		}
+		for name, appIDs := range localCollections {
+			collection, ok := client.mapCollectionName(name)
+			if !ok {
+				continue
+			}
+			for _, appID := range appIDs {
+				client.addToCollection(appID, collection)
+			}
+		}
+	}
+
 	return &client, nil
 }
 
-// GetWishlist gets all games on the authenticated user's wishlist with extra
-// data populated by getting the Steam App info
+// WithAPIOptions passes options through to the underlying Steam API client
+func WithAPIOptions(opts ...steam.ClientOption) ClientOption {
+	return func(sc *SteamClient) {
+		sc.settings.apiOptions = append(sc.settings.apiOptions, opts...)
+	}
+}
+
+// WithClock tells the time with c, which decides whether games are released
+// yet. Clients use the system clock in the local time zone by default
+func WithClock(c clock.Clock) ClientOption {
+	return func(sc *SteamClient) {
+		sc.clock = c
+	}
+}
+
+// WithInstallPath reads library Collections from the Steam client installed at
+// path instead of the default install location for the OS
+func WithInstallPath(path string) ClientOption {
+	return func(sc *SteamClient) {
+		sc.settings.installPath = strings.TrimSpace(path)
+	}
+}
+
+// WithCollectionNames maps the names of Steam library collections to
+// kanbanchan Collections (CollectionFinished, CollectionPlaying or
+// CollectionUpNext). Without a mapping, Steam collections are matched to
+// Collections with the same name
+func WithCollectionNames(names map[string]string) ClientOption {
+	return func(sc *SteamClient) {
+		sc.settings.collectionNames = names
+	}
+}
+
+// GetWishlist gets all games on the authenticated user's wishlist, keyed by
+// app ID, with extra data populated by getting the Steam App info. If some apps can't be
+// retrieved, the remaining games are returned along with a *PartialError
 func (sc *SteamClient) GetWishlist() (*map[string]SteamGame, error) {
 	wishlist, err := sc.steam.GetUserWishlist(sc.steamID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wishlist for user id %s: %s", sc.steamID, err.Error())
 	}
 
+	var appIDs []string
+	for _, wishlistApp := range wishlist {
+		appIDs = append(appIDs, wishlistApp.ID)
+	}
+	steamApps, failed := sc.fetchApps(appIDs)
+
 	games := make(map[string]SteamGame)
 	for _, wishlistApp := range wishlist {
-		steamApp, err := sc.steam.GetApp(wishlistApp.ID)
-		if err != nil {
-			return nil, err
-		}
-		var genres []string
-		for _, genre := range steamApp.Data.Genres {
-			genres = append(genres, genre.Description)
-		}
-		game := SteamGame{
-			ID:          wishlistApp.ID,
-			Name:        steamApp.Data.Name,
-			HeaderImage: steamApp.Data.HeaderImage,
-			Genres:      genres,
-		}
-		if strings.ToLower(steamApp.Data.ReleaseDate.Date) != "to be announced" &&
-			steamApp.Data.ReleaseDate.Date != "" {
-			releaseDate, err := ParseSteamDate(steamApp.Data.ReleaseDate.Date)
-			if err != nil {
-				return nil, err
-			}
-			game.ReleaseDate = releaseDate
+		steamApp, ok := steamApps[wishlistApp.ID]
+		if !ok {
+			continue
 		}
-		_, ok := games[game.Name]
+		game := newSteamGame(steamApp)
+		game.ID = wishlistApp.ID
+		game.ReleaseDate = ParseReleaseDate(steamApp.Data.ReleaseDate.Date)
+		sc.markReleased(&game)
+		_, ok = games[game.ID]
 		if !ok {
-			games[game.Name] = game
+			games[game.ID] = game
 		}
 	}
 
-	return &games, nil
+	return &games, newPartialError(failed)
 }
 
-// GetLibrary gets all games owned by the authenticated user with extra
-// data populated by getting the Steam App info
+// GetLibrary gets all games owned by the authenticated user, keyed by app ID,
+// with extra data populated by getting the Steam App info. If some apps can't be
+// retrieved, the remaining games are returned along with a *PartialError
 func (sc *SteamClient) GetLibrary() (*map[string]SteamGame, error) {
 	library, err := sc.steam.GetUserOwnedGames(sc.steamID)
 	if err != nil {
@@ -183,76 +255,131 @@ func (sc *SteamClient) GetLibrary() (*map[string]SteamGame, error) {
 	}
 
 	collectionMap := make(map[string]map[string]bool)
+	var appIDs []string
 	for _, game := range library.Response.Games {
 		collections, err := libraryCollectionCheck(sc, game.AppID.String())
 		if err != nil {
 			return nil, err
 		}
 		collectionMap[game.AppID.String()] = collections
+		if len(collections) > 0 {
+			appIDs = append(appIDs, game.AppID.String())
+		}
 	}
+	steamApps, failed := sc.fetchApps(appIDs)
 
-	games := make(map[string]SteamGame)
+	var statsAppIDs []string
 	for _, game := range library.Response.Games {
-		if len(collectionMap[game.AppID.String()]) > 0 {
-			steamApp, err := sc.steam.GetApp(string(game.AppID))
-			if err != nil {
-				return nil, err
-			}
-			var genres []string
-			for _, genre := range steamApp.Data.Genres {
-				genres = append(genres, genre.Description)
-			}
-			releaseDate, err := ParseSteamDate(steamApp.Data.ReleaseDate.Date)
-			if err != nil {
-				return nil, err
-			}
-			iPlaytime, err := game.Playtime.Int64()
-			if err != nil {
-				return nil, err
-			}
-			iPlaytimeWindows, err := game.PlaytimeWindows.Int64()
-			if err != nil {
-				return nil, err
-			}
-			iPlaytimeMac, err := game.PlaytimeMac.Int64()
-			if err != nil {
-				return nil, err
-			}
-			iPlaytimeLinux, err := game.PlaytimeLinux.Int64()
-			if err != nil {
-				return nil, err
-			}
-			iPlaytimeDisconnected, err := game.PlaytimeDisconnected.Int64()
-			if err != nil {
-				return nil, err
-			}
-			iLastPlayed, err := game.LastPlayed.Int64()
-			if err != nil {
-				return nil, err
-			}
-			game := SteamGame{
-				ID:                       string(steamApp.Data.AppID),
-				Name:                     steamApp.Data.Name,
-				HeaderImage:              steamApp.Data.HeaderImage,
-				Genres:                   genres,
-				ReleaseDate:              releaseDate,
-				Playtime:                 ParsePlaytime(iPlaytime),
-				PlaytimeWindows:          ParsePlaytime(iPlaytimeWindows),
-				PlaytimeMac:              ParsePlaytime(iPlaytimeMac),
-				PlaytimeLinux:            ParsePlaytime(iPlaytimeLinux),
-				PlaytimeDisconnected:     ParsePlaytime(iPlaytimeDisconnected),
-				LastPlayed:               ParsePlaytime(iLastPlayed),
-				HasCommunityVisibleStats: game.HasCommunityVisibleStats,
-				Collections:              collectionMap[game.AppID.String()],
-			}
-			_, ok := games[game.Name]
-			if !ok {
-				games[game.Name] = game
-			}
+		if _, ok := steamApps[game.AppID.String()]; ok && game.HasCommunityVisibleStats {
+			statsAppIDs = append(statsAppIDs, game.AppID.String())
 		}
 	}
+	completions, completionsFailed := sc.fetchCompletions(statsAppIDs)
+	failed = append(failed, completionsFailed...)
 
-	return &games, nil
+	install, installFailed, err := sc.readInstallState()
+	if err != nil {
+		return nil, err
+	}
+	failed = append(failed, installFailed...)
+
+	games := make(map[string]SteamGame)
+	for _, libraryGame := range library.Response.Games {
+		steamApp, ok := steamApps[libraryGame.AppID.String()]
+		if !ok || install.unreadable[libraryGame.AppID.String()] {
+			continue
+		}
+		game, err := newLibraryGame(libraryGame, steamApp, collectionMap[libraryGame.AppID.String()])
+		if err != nil {
+			failed = append(failed, AppError{AppID: libraryGame.AppID.String(), Err: err})
+			continue
+		}
+		sc.markReleased(game)
+		game.Achievements = completions[libraryGame.AppID.String()]
+		game.InstallStateKnown = install.known
+		if installedGame, ok := install.installed[libraryGame.AppID.String()]; ok {
+			game.Installed = true
+			game.SizeOnDisk = installedGame.SizeOnDisk
+		}
+		_, ok = games[game.ID]
+		if !ok {
+			games[game.ID] = *game
+		}
+	}
+
+	return &games, newPartialError(failed)
+}
+
+// newLibraryGame combines an owned game with its Steam App info
+func newLibraryGame(libraryGame steam.OwnedApp, steamApp *steam.SteamApp, collections map[string]bool) (*SteamGame, error) {
+	playtime, err := newPlaytime(libraryGame)
+	if err != nil {
+		return nil, err
+	}
+	lastPlayed, err := lastPlayedTime(libraryGame.LastPlayed)
+	if err != nil {
+		return nil, err
+	}
+	game := newSteamGame(steamApp)
+	game.ReleaseDate = ParseReleaseDate(steamApp.Data.ReleaseDate.Date)
+	game.Owned = true
+	game.Playtime = playtime
+	game.LastPlayed = lastPlayed
+	game.HasCommunityVisibleStats = libraryGame.HasCommunityVisibleStats
+	game.Collections = collections
+	return &game, nil
+}
+
+// markReleased clears ComingSoon once a game's release day has arrived in the
+// clock's time zone, since the store can keep a game marked as coming soon for
+// part of its release day. Release dates without a day, like "2025", are left alone
+func (sc *SteamClient) markReleased(game *SteamGame) {
+	// Release dates are date-only, midnight UTC, so clock.Day reads them as
+	// the day they name rather than converting them to the clock's zone
+	if game.ComingSoon && game.ReleaseDate.Exact() && clock.OnOrBeforeToday(game.ReleaseDate.Start, sc.clock) {
+		game.ComingSoon = false
+	}
+}
+
+// newSteamGame populates a SteamGame with the store details of a Steam App
+func newSteamGame(steamApp *steam.SteamApp) SteamGame {
+	data := steamApp.Data
+	game := SteamGame{
+		ID:               string(data.AppID),
+		Name:             data.Name,
+		HeaderImage:      data.HeaderImage,
+		Developers:       data.Developers,
+		Publishers:       data.Publishers,
+		ShortDescription: html.UnescapeString(data.ShortDescription),
+		IsFree:           data.IsFree,
+		DLCCount:         len(data.DLC),
+		ComingSoon:       data.ReleaseDate.ComingSoon,
+	}
+	for _, genre := range data.Genres {
+		game.Genres = append(game.Genres, genre.Description)
+	}
+	for _, category := range data.Categories {
+		game.Categories = append(game.Categories, category.Description)
+	}
+	if data.Platforms.Windows {
+		game.Platforms = append(game.Platforms, PlatformWindows)
+	}
+	if data.Platforms.Mac {
+		game.Platforms = append(game.Platforms, PlatformMac)
+	}
+	if data.Platforms.Linux {
+		game.Platforms = append(game.Platforms, PlatformLinux)
+	}
+	if data.Metacritic != nil {
+		game.MetacriticScore = data.Metacritic.Score
+	}
+	if data.PriceOverview != nil {
+		game.Price = data.PriceOverview.FinalFormatted
+	}
+	for _, screenshot := range data.Screenshots {
+		game.Screenshots = append(game.Screenshots, screenshot.Full)
+	}
+	return game
 }
 
 // GetApp gets a Steam App
@@ -273,56 +400,86 @@ func (sc *SteamClient) GetAppByName(appName string) (*steam.SteamApp, error) {
 	return steamApp, nil
 }
 
-// ParseSteamDate attempts to parse various formats of dates used by Steam apps
-func ParseSteamDate(steamDate string) (time.Time, error) {
-	date, err := time.Parse(steamDateFormat, steamDate)
-	if err != nil {
-		// fmt.Println("failed to parse release date in standard format; attempting alt format...")
-	} else {
-		return date, nil
-	}
+// InvalidateApp removes a Steam App from the app details cache
+func (sc *SteamClient) InvalidateApp(appID string) error {
+	return sc.steam.InvalidateApp(appID)
+}
 
-	date, err = time.Parse(steamDateAltFormat, steamDate)
+// SearchApps returns up to limit Steam apps whose names best match appName
+func (sc *SteamClient) SearchApps(appName string, limit int) ([]steam.AppMatch, error) {
+	matches, err := sc.steam.SearchApps(appName, limit)
 	if err != nil {
-		// fmt.Println("failed to parse release date in alt format; attempting year format...")
-	} else {
-		return date, nil
+		return nil, err
 	}
+	return matches, nil
+}
 
-	date, err = time.Parse(steamDateYearFormat, steamDate)
+// RefreshAppList downloads the Steam app list used by SearchApps, replacing the cached copy
+func (sc *SteamClient) RefreshAppList() error {
+	return sc.steam.RefreshAppList()
+}
+
+// OwnedAppIDs returns the app IDs of every game the authenticated user owns,
+// without retrieving any app details
+func (sc *SteamClient) OwnedAppIDs() (map[string]bool, error) {
+	library, err := sc.steam.GetUserOwnedGames(sc.steamID)
 	if err != nil {
-		// fmt.Println("failed to parse release date in year format")
-		return time.Time{}, nil
-	} else {
-		return date, nil
+		return nil, fmt.Errorf("failed to get owned games for user id %s: %s", sc.steamID, err.Error())
+	}
+	owned := make(map[string]bool)
+	for _, game := range library.Response.Games {
+		owned[game.AppID.String()] = true
 	}
+	return owned, nil
 }
 
-// ParsePlaytime parses a JSON timestamp into a Golang time.Time type
-func ParsePlaytime(timestamp int64) time.Time {
-	return time.Unix(timestamp, 0)
+// AppCollections returns the collections an app is in, such as CollectionFinished
+func (sc *SteamClient) AppCollections(appID string) map[string]bool {
+	collections, _ := libraryCollectionCheck(sc, appID)
+	return collections
 }
 
-// libraryCollectionCheck checks which manually tracked Library Collections a game is in
+// CheckKey makes a single Web API request for the authenticated user to
+// confirm the Steam key and ID are usable
+func (sc *SteamClient) CheckKey() error {
+	_, err := sc.steam.GetRecentlyPlayedGames(sc.steamID, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get recently played games for user id %s: %s", sc.steamID, err.Error())
+	}
+	return nil
+}
+
+// libraryCollectionCheck checks which Library Collections a game is in
 func libraryCollectionCheck(sc *SteamClient, appID string) (map[string]bool, error) {
 	collections := make(map[string]bool)
-	for _, id := range sc.collections.finished {
-		if id == appID {
-			collections[CollectionFinished] = true
-			break
-		}
+	for collection := range sc.collections[appID] {
+		collections[collection] = true
+	}
+	return collections, nil
+}
+
+// addToCollection records that an app is in a Library Collection
+func (sc *SteamClient) addToCollection(appID string, collection string) {
+	if sc.collections[appID] == nil {
+		sc.collections[appID] = make(map[string]bool)
 	}
-	for _, id := range sc.collections.upNext {
-		if id == appID {
-			collections[CollectionUpNext] = true
-			break
+	sc.collections[appID][collection] = true
+}
+
+// mapCollectionName returns the kanbanchan Collection a Steam collection is tracked as
+func (sc *SteamClient) mapCollectionName(name string) (string, bool) {
+	if len(sc.settings.collectionNames) > 0 {
+		for steamName, collection := range sc.settings.collectionNames {
+			if strings.EqualFold(strings.TrimSpace(steamName), strings.TrimSpace(name)) {
+				return collection, true
+			}
 		}
+		return "", false
 	}
-	for _, id := range sc.collections.playing {
-		if id == appID {
-			collections[CollectionPlaying] = true
-			break
+	for _, collection := range []string{CollectionFinished, CollectionPlaying, CollectionUpNext} {
+		if strings.EqualFold(collection, strings.TrimSpace(name)) {
+			return collection, true
 		}
 	}
-	return collections, nil
+	return "", false
 }
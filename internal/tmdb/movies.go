@@ -0,0 +1,77 @@
+package tmdb
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Movie contains info about a movie
+type Movie struct {
+	ID             string        `json:"id"`
+	Title          string        `json:"title"`
+	ReleaseDate    time.Time     `json:"releaseDate"` // zero when unknown
+	Runtime        time.Duration `json:"runtime"`     // 0 when unknown
+	Genres         []string      `json:"genres,omitempty"`
+	Directors      []string      `json:"directors,omitempty"`
+	Overview       string        `json:"overview,omitempty"`
+	PosterURL      string        `json:"posterURL,omitempty"`
+	URL            string        `json:"url"`
+	WatchProviders []string      `json:"watchProviders,omitempty"` // streaming services in the client's region
+}
+
+// GetMovie gets a movie by TMDB ID, along with where it can be streamed
+func (tc *TMDBClient) GetMovie(movieID string) (*Movie, error) {
+	id, err := strconv.Atoi(movieID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tmdb movie id %s", movieID)
+	}
+	details, err := tc.tmdb.GetMovie(id)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := tc.tmdb.GetMovieWatchProviders(id)
+	if err != nil {
+		return nil, err
+	}
+	releaseDate, err := parseDate(details.ReleaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse release date of movie id %s: %s", movieID, err.Error())
+	}
+
+	return &Movie{
+		ID:             movieID,
+		Title:          details.Title,
+		ReleaseDate:    releaseDate,
+		Runtime:        time.Duration(details.Runtime) * time.Minute,
+		Genres:         genreNames(details.Genres),
+		Directors:      crewNames(details.Credits, "Director"),
+		Overview:       details.Overview,
+		PosterURL:      tc.tmdb.ImageURL(details.PosterPath, posterSize),
+		URL:            pageURL("movie", details.ID),
+		WatchProviders: tc.streamingProviders(providers),
+	}, nil
+}
+
+// FindMovie searches for a movie by title, narrowed to a release year when it
+// isn't 0, and gets the one whose title matches. Nil is returned when no
+// result is a confident match
+func (tc *TMDBClient) FindMovie(title string, year int) (*Movie, error) {
+	results, err := tc.tmdb.SearchMovies(title, year)
+	if err != nil {
+		return nil, err
+	}
+
+	want := normalizeTitle(title)
+	for _, result := range results {
+		if normalizeTitle(result.Title) == want || normalizeTitle(result.OriginalTitle) == want {
+			return tc.GetMovie(strconv.Itoa(result.ID))
+		}
+	}
+	// A single result for the right year is a match even if the title is
+	// written differently
+	if year > 0 && len(results) == 1 {
+		return tc.GetMovie(strconv.Itoa(results[0].ID))
+	}
+	return nil, nil
+}
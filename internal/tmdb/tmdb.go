@@ -0,0 +1,133 @@
+package tmdb
+
+import (
+	"context"
+	"fmt"
+	"kanbanchan/internal/aws"
+	"kanbanchan/pkg/tmdb"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	defaultRegion = "US"
+	posterSize    = "w500"
+	tmdbURL       = "https://www.themoviedb.org"
+	tmdbDate      = "2006-01-02"
+)
+
+// TMDBClient contains a client and the region watch providers are looked up in
+type TMDBClient struct {
+	tmdb     *tmdb.TMDBClient
+	settings struct {
+		apiOptions []tmdb.ClientOption
+		region     string
+	}
+}
+
+// ClientOption configures optional settings on a TMDBClient
+type ClientOption func(*TMDBClient)
+
+// NewClient creates a client authenticated with the TMDB key in secrets
+func NewClient(ctx context.Context, opts ...ClientOption) (*TMDBClient, error) {
+	var client TMDBClient
+	var secrets, err = aws.GetSecrets()
+	if err != nil {
+		return nil, err
+	}
+	client.settings.region = defaultRegion
+	for _, opt := range opts {
+		opt(&client)
+	}
+
+	tmdbClient, err := tmdb.NewClient(ctx, secrets.TMDB.Key, client.settings.apiOptions...)
+	if err != nil {
+		return nil, err
+	}
+	client.tmdb = tmdbClient
+	return &client, nil
+}
+
+// WithAPIOptions passes options through to the underlying TMDB API client
+func WithAPIOptions(opts ...tmdb.ClientOption) ClientOption {
+	return func(tc *TMDBClient) {
+		tc.settings.apiOptions = append(tc.settings.apiOptions, opts...)
+	}
+}
+
+// WithRegion looks up watch providers in a region (e.g. "GB") instead of the US
+func WithRegion(region string) ClientOption {
+	return func(tc *TMDBClient) {
+		if region = strings.ToUpper(strings.TrimSpace(region)); region != "" {
+			tc.settings.region = region
+		}
+	}
+}
+
+// streamingProviders returns the names of the services a title can be
+// streamed on in the client's region, without renting or buying it
+func (tc *TMDBClient) streamingProviders(providers *tmdb.WatchProviders) []string {
+	region, ok := providers.Results[tc.settings.region]
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, list := range [][]tmdb.WatchProvider{region.Flatrate, region.Free, region.Ads} {
+		for _, provider := range list {
+			if !seen[provider.Name] {
+				seen[provider.Name] = true
+				names = append(names, provider.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseDate parses a TMDB date, which is empty when unknown
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(tmdbDate, date)
+}
+
+// normalizeTitle lowercases a title and drops punctuation so titles can be
+// compared loosely
+func normalizeTitle(title string) string {
+	var normalized strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
+
+// genreNames returns the names of genres
+func genreNames(genres []tmdb.Genre) []string {
+	var names []string
+	for _, genre := range genres {
+		names = append(names, genre.Name)
+	}
+	return names
+}
+
+// crewNames returns the names of crew members with a job, like "Director"
+func crewNames(credits tmdb.Credits, job string) []string {
+	var names []string
+	for _, member := range credits.Crew {
+		if member.Job == job {
+			names = append(names, member.Name)
+		}
+	}
+	return names
+}
+
+// pageURL returns the TMDB website page of a movie or show
+func pageURL(kind string, id int) string {
+	return fmt.Sprintf("%s/%s/%d", tmdbURL, kind, id)
+}
@@ -0,0 +1,170 @@
+package tmdb
+
+import (
+	"fmt"
+	"kanbanchan/pkg/tmdb"
+	"strconv"
+	"time"
+)
+
+// Show statuses TMDB reports for shows that won't get new episodes
+const (
+	ShowEnded    = "Ended"
+	ShowCanceled = "Canceled"
+)
+
+// Show contains info about a TV show and the episodes it has aired
+type Show struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Status         string    `json:"status"`
+	FirstAirDate   time.Time `json:"firstAirDate"` // zero when unknown
+	Genres         []string  `json:"genres,omitempty"`
+	Networks       []string  `json:"networks,omitempty"`
+	Overview       string    `json:"overview,omitempty"`
+	PosterURL      string    `json:"posterURL,omitempty"`
+	URL            string    `json:"url"`
+	WatchProviders []string  `json:"watchProviders,omitempty"` // streaming services in the client's region
+	Seasons        []Season  `json:"seasons"`                  // regular seasons in order, without specials
+	LastEpisode    *Episode  `json:"lastEpisode,omitempty"`    // the latest episode that has aired
+	NextEpisode    *Episode  `json:"nextEpisode,omitempty"`    // the next episode due to air, if announced
+}
+
+// Season is a regular season of a show
+type Season struct {
+	Number       int       `json:"number"`
+	EpisodeCount int       `json:"episodeCount"`
+	AirDate      time.Time `json:"airDate"` // zero when unannounced
+}
+
+// Episode is a single episode of a show
+type Episode struct {
+	Season  int       `json:"season"`
+	Number  int       `json:"number"`
+	Title   string    `json:"title,omitempty"`
+	AirDate time.Time `json:"airDate"` // zero when unannounced
+}
+
+// Code formats an episode like "S02E05"
+func (e Episode) Code() string {
+	return fmt.Sprintf("S%02dE%02d", e.Season, e.Number)
+}
+
+// Ended reports whether the show won't air any more episodes
+func (s Show) Ended() bool {
+	return s.Status == ShowEnded || s.Status == ShowCanceled
+}
+
+// EpisodeIndex returns how many regular episodes there are up to and
+// including an episode, counting every episode of the seasons before it
+func (s Show) EpisodeIndex(season int, episode int) int {
+	index := 0
+	for _, s := range s.Seasons {
+		if s.Number < season {
+			index += s.EpisodeCount
+		}
+	}
+	return index + episode
+}
+
+// SeasonFinale reports whether an episode is the last of its season, which
+// it's taken to be when the season's length isn't known
+func (s Show) SeasonFinale(e Episode) bool {
+	for _, season := range s.Seasons {
+		if season.Number == e.Season && season.EpisodeCount > e.Number {
+			return false
+		}
+	}
+	return true
+}
+
+// GetShow gets a show by TMDB ID, along with where it can be streamed
+func (tc *TMDBClient) GetShow(showID string) (*Show, error) {
+	id, err := strconv.Atoi(showID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tmdb tv id %s", showID)
+	}
+	details, err := tc.tmdb.GetTV(id)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := tc.tmdb.GetTVWatchProviders(id)
+	if err != nil {
+		return nil, err
+	}
+
+	show := Show{
+		ID:             showID,
+		Title:          details.Name,
+		Status:         details.Status,
+		Genres:         genreNames(details.Genres),
+		Overview:       details.Overview,
+		PosterURL:      tc.tmdb.ImageURL(details.PosterPath, posterSize),
+		URL:            pageURL("tv", details.ID),
+		WatchProviders: tc.streamingProviders(providers),
+	}
+	show.FirstAirDate, err = parseDate(details.FirstAirDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse first air date of tv id %s: %s", showID, err.Error())
+	}
+	for _, network := range details.Networks {
+		show.Networks = append(show.Networks, network.Name)
+	}
+	for _, season := range details.Seasons {
+		if season.SeasonNumber < 1 { // specials
+			continue
+		}
+		airDate, err := parseDate(season.AirDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse air date of tv id %s season %d: %s", showID, season.SeasonNumber, err.Error())
+		}
+		show.Seasons = append(show.Seasons, Season{Number: season.SeasonNumber, EpisodeCount: season.EpisodeCount, AirDate: airDate})
+	}
+	show.LastEpisode, err = newEpisode(details.LastEpisodeToAir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse last episode of tv id %s: %s", showID, err.Error())
+	}
+	show.NextEpisode, err = newEpisode(details.NextEpisodeToAir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse next episode of tv id %s: %s", showID, err.Error())
+	}
+	return &show, nil
+}
+
+// FindShow searches for a show by title, narrowed to the year it first aired
+// when it isn't 0, and gets the one whose title matches. Nil is returned when
+// no result is a confident match
+func (tc *TMDBClient) FindShow(title string, year int) (*Show, error) {
+	results, err := tc.tmdb.SearchTV(title, year)
+	if err != nil {
+		return nil, err
+	}
+
+	want := normalizeTitle(title)
+	for _, result := range results {
+		if normalizeTitle(result.Name) == want || normalizeTitle(result.OriginalName) == want {
+			return tc.GetShow(strconv.Itoa(result.ID))
+		}
+	}
+	if year > 0 && len(results) == 1 {
+		return tc.GetShow(strconv.Itoa(results[0].ID))
+	}
+	return nil, nil
+}
+
+// newEpisode converts a TMDB episode, which may be missing
+func newEpisode(episode *tmdb.Episode) (*Episode, error) {
+	if episode == nil || episode.SeasonNumber < 1 {
+		return nil, nil
+	}
+	airDate, err := parseDate(episode.AirDate)
+	if err != nil {
+		return nil, err
+	}
+	return &Episode{
+		Season:  episode.SeasonNumber,
+		Number:  episode.EpisodeNumber,
+		Title:   episode.Name,
+		AirDate: airDate,
+	}, nil
+}
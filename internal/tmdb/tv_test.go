@@ -0,0 +1,70 @@
+package tmdb
+
+import "testing"
+
+func TestShowEpisodeIndex(t *testing.T) {
+	show := Show{Seasons: []Season{
+		{Number: 1, EpisodeCount: 10},
+		{Number: 2, EpisodeCount: 8},
+		{Number: 3},
+	}}
+
+	tests := []struct {
+		name    string
+		season  int
+		episode int
+		want    int
+	}{
+		{name: "nothing watched", season: 0, episode: 0, want: 0},
+		{name: "first episode", season: 1, episode: 1, want: 1},
+		{name: "first season finale", season: 1, episode: 10, want: 10},
+		{name: "second season premiere", season: 2, episode: 1, want: 11},
+		{name: "second season finale", season: 2, episode: 8, want: 18},
+		{name: "season of unknown length", season: 3, episode: 4, want: 22},
+		{name: "season TMDB doesn't list", season: 5, episode: 2, want: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := show.EpisodeIndex(tt.season, tt.episode); got != tt.want {
+				t.Errorf("EpisodeIndex(%d, %d) = %d, want %d", tt.season, tt.episode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowSeasonFinale(t *testing.T) {
+	show := Show{Seasons: []Season{
+		{Number: 1, EpisodeCount: 10},
+		{Number: 2, EpisodeCount: 8},
+		{Number: 3},
+	}}
+
+	tests := []struct {
+		name    string
+		episode Episode
+		want    bool
+	}{
+		{name: "mid-season", episode: Episode{Season: 1, Number: 5}, want: false},
+		{name: "last episode", episode: Episode{Season: 2, Number: 8}, want: true},
+		{name: "past the episode count", episode: Episode{Season: 2, Number: 9}, want: true},
+		{name: "season of unknown length", episode: Episode{Season: 3, Number: 1}, want: true},
+		{name: "season TMDB doesn't list", episode: Episode{Season: 4, Number: 1}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := show.SeasonFinale(tt.episode); got != tt.want {
+				t.Errorf("SeasonFinale(%s) = %t, want %t", tt.episode.Code(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowEnded(t *testing.T) {
+	for status, want := range map[string]bool{ShowEnded: true, ShowCanceled: true, "Returning Series": false, "": false} {
+		if got := (Show{Status: status}).Ended(); got != want {
+			t.Errorf("Show{Status: %q}.Ended() = %t, want %t", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package anilist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	aniListAPIURL = "https://graphql.anilist.co"
+
+	defaultUserAgent = "kanbanchan"
+
+	// listChunkSize is how many entries are requested per page of a list
+	// collection, which is the most AniList allows
+	listChunkSize = 500
+)
+
+// MediaListStatus is where an entry is on a user's list
+type MediaListStatus string
+
+const (
+	StatusCurrent   MediaListStatus = "CURRENT"
+	StatusPlanning  MediaListStatus = "PLANNING"
+	StatusCompleted MediaListStatus = "COMPLETED"
+	StatusDropped   MediaListStatus = "DROPPED"
+	StatusPaused    MediaListStatus = "PAUSED"
+	StatusRepeating MediaListStatus = "REPEATING"
+)
+
+// AniListClient queries the AniList GraphQL API
+type AniListClient struct {
+	ctx        context.Context
+	httpClient *http.Client
+	apiURL     string
+	userAgent  string
+	token      string
+}
+
+// ClientOption configures optional settings on an AniListClient
+type ClientOption func(*AniListClient)
+
+// FuzzyDate is a date that may only be known to the year or month
+type FuzzyDate struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// MediaTitle is a title in each of the forms AniList keeps
+type MediaTitle struct {
+	Romaji        string `json:"romaji"`
+	English       string `json:"english"`
+	Native        string `json:"native"`
+	UserPreferred string `json:"userPreferred"`
+}
+
+// Media defines an anime or manga
+type Media struct {
+	ID         int        `json:"id"`
+	IDMal      int        `json:"idMal"`
+	Title      MediaTitle `json:"title"`
+	Format     string     `json:"format"`
+	Status     string     `json:"status"`
+	Episodes   int        `json:"episodes"` // 0 while the episode count is unknown
+	Season     string     `json:"season"`
+	SeasonYear int        `json:"seasonYear"`
+	Genres     []string   `json:"genres"`
+	SiteURL    string     `json:"siteUrl"`
+	CoverImage struct {
+		Large string `json:"large"`
+	} `json:"coverImage"`
+	StartDate FuzzyDate `json:"startDate"`
+}
+
+// MediaListEntry defines a single entry on a user's list
+type MediaListEntry struct {
+	ID          int             `json:"id"`
+	Status      MediaListStatus `json:"status"`
+	Score       float64         `json:"score"` // out of 10, 0 when unscored
+	Progress    int             `json:"progress"`
+	UpdatedAt   int64           `json:"updatedAt"`
+	StartedAt   FuzzyDate       `json:"startedAt"`
+	CompletedAt FuzzyDate       `json:"completedAt"`
+	Media       Media           `json:"media"`
+}
+
+// StatusError is returned when AniList responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Messages   []string
+}
+
+func (e *StatusError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("unexpected response status %s", e.Status)
+	}
+	return fmt.Sprintf("unexpected response status %s: %s", e.Status, strings.Join(e.Messages, "; "))
+}
+
+// graphQLResponse is the envelope every GraphQL response comes in
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+const mediaListCollectionQuery = `query ($userName: String, $statuses: [MediaListStatus], $chunk: Int, $perChunk: Int) {
+  MediaListCollection(userName: $userName, type: ANIME, status_in: $statuses, chunk: $chunk, perChunk: $perChunk) {
+    hasNextChunk
+    lists {
+      isCustomList
+      entries {
+        id
+        status
+        score(format: POINT_10_DECIMAL)
+        progress
+        updatedAt
+        startedAt { year month day }
+        completedAt { year month day }
+        media {
+          id
+          idMal
+          title { romaji english native userPreferred }
+          format
+          status
+          episodes
+          season
+          seasonYear
+          genres
+          siteUrl
+          coverImage { large }
+          startDate { year month day }
+        }
+      }
+    }
+  }
+}`
+
+// NewClient creates a new AniList client. Public lists can be read without
+// a token, see WithToken for private ones
+func NewClient(ctx context.Context, opts ...ClientOption) (*AniListClient, error) {
+	client := AniListClient{
+		httpClient: http.DefaultClient,
+		apiURL:     aniListAPIURL,
+		userAgent:  defaultUserAgent,
+	}
+	if ctx == nil {
+		client.ctx = context.Background()
+	} else {
+		client.ctx = ctx
+	}
+	for _, opt := range opts {
+		opt(&client)
+	}
+	return &client, nil
+}
+
+// WithHTTPClient overrides the http.Client used for all requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(ac *AniListClient) {
+		if httpClient != nil {
+			ac.httpClient = httpClient
+		}
+	}
+}
+
+// WithAPIURL overrides the URL of the GraphQL API (graphql.anilist.co), such
+// as to point the client at a local fake
+func WithAPIURL(apiURL string) ClientOption {
+	return func(ac *AniListClient) {
+		if apiURL = strings.TrimRight(strings.TrimSpace(apiURL), "/"); apiURL != "" {
+			ac.apiURL = apiURL
+		}
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(ac *AniListClient) {
+		if userAgent = strings.TrimSpace(userAgent); userAgent != "" {
+			ac.userAgent = userAgent
+		}
+	}
+}
+
+// WithToken authenticates requests with an OAuth access token, which is
+// needed to read private lists
+func WithToken(token string) ClientOption {
+	return func(ac *AniListClient) {
+		ac.token = strings.TrimSpace(token)
+	}
+}
+
+// GetUserAnimeList returns the entries on a user's anime list with any of the
+// given statuses, or every entry when none are given. Entries on custom lists
+// are only returned once
+func (ac *AniListClient) GetUserAnimeList(userName string, statuses ...MediaListStatus) ([]MediaListEntry, error) {
+	if strings.TrimSpace(userName) == "" {
+		return nil, fmt.Errorf("request made with empty user name")
+	}
+
+	var entries []MediaListEntry
+	for chunk := 1; ; chunk++ {
+		var data struct {
+			MediaListCollection struct {
+				HasNextChunk bool `json:"hasNextChunk"`
+				Lists        []struct {
+					IsCustomList bool             `json:"isCustomList"`
+					Entries      []MediaListEntry `json:"entries"`
+				} `json:"lists"`
+			} `json:"MediaListCollection"`
+		}
+		variables := map[string]interface{}{
+			"userName": userName,
+			"chunk":    chunk,
+			"perChunk": listChunkSize,
+		}
+		if len(statuses) > 0 {
+			variables["statuses"] = statuses
+		}
+		err := ac.Query(mediaListCollectionQuery, variables, &data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get anime list for user %s: %s", userName, err.Error())
+		}
+
+		for _, list := range data.MediaListCollection.Lists {
+			if !list.IsCustomList {
+				entries = append(entries, list.Entries...)
+			}
+		}
+		if !data.MediaListCollection.HasNextChunk {
+			return entries, nil
+		}
+	}
+}
+
+// Query runs a GraphQL query and decodes its data into result
+func (ac *AniListClient) Query(query string, variables map[string]interface{}, result interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode query: %s", err.Error())
+	}
+
+	req, err := http.NewRequestWithContext(ac.ctx, http.MethodPost, ac.apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %s", err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", ac.userAgent)
+	if ac.token != "" {
+		req.Header.Set("Authorization", "Bearer "+ac.token)
+	}
+
+	resp, err := ac.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %s", err.Error())
+	}
+
+	// Errors are reported in the body, alongside a 4xx status or partial data
+	var response graphQLResponse
+	decodeErr := json.Unmarshal(body, &response)
+	var messages []string
+	for _, graphQLErr := range response.Errors {
+		messages = append(messages, graphQLErr.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Messages: messages}
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %s", decodeErr.Error())
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("query failed: %s", strings.Join(messages, "; "))
+	}
+	err = json.Unmarshal(response.Data, result)
+	if err != nil {
+		return fmt.Errorf("failed to decode response data: %s", err.Error())
+	}
+	return nil
+}
+
+// Known reports whether at least the year is known
+func (fd FuzzyDate) Known() bool {
+	return fd.Year > 0
+}
+
+// Time returns the date as midnight UTC, using the first month or day for
+// whichever parts aren't known. The zero time is returned when the year isn't
+func (fd FuzzyDate) Time() time.Time {
+	if !fd.Known() {
+		return time.Time{}
+	}
+	month, day := fd.Month, fd.Day
+	if month < 1 {
+		month = 1
+	}
+	if day < 1 {
+		day = 1
+	}
+	return time.Date(fd.Year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -2,17 +2,27 @@ package notion
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/jomei/notionapi"
 )
 
+const (
+	notionAPIURL  = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28" // the version notionapi sends
+)
+
 // NotionClient contains an authenticated Notion client
 type NotionClient struct {
-	ctx    context.Context
-	client *notionapi.Client
+	ctx        context.Context
+	client     *notionapi.Client
+	authToken  string
+	httpClient *http.Client // for the requests notionapi can't make
 }
 
 // NewClient creates an authenticated Notion client
@@ -24,6 +34,8 @@ func NewClient(ctx context.Context, authToken string) (*NotionClient, error) {
 		client.ctx = ctx
 	}
 	client.client = notionapi.NewClient(notionapi.Token(authToken))
+	client.authToken = authToken
+	client.httpClient = http.DefaultClient
 	return &client, nil
 }
 
@@ -37,6 +49,104 @@ func (nc *NotionClient) GetDatabase(databaseID string) (*notionapi.Database, err
 	return db, nil
 }
 
+// UpdateDatabase updates the title or property configs of the specified
+// database. New properties are added and existing ones replaced by name
+func (nc *NotionClient) UpdateDatabase(databaseID string, opts *notionapi.DatabaseUpdateRequest) (*notionapi.Database, error) {
+	db, err := nc.client.Database.Update(nc.ctx, notionapi.DatabaseID(databaseID), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update database id %s: %s", databaseID, err.Error())
+	}
+
+	return db, nil
+}
+
+// GetStatusOptions retrieves the option names of every status property of the
+// specified database, keyed by property name. notionapi doesn't decode status
+// property configs, so the database is fetched directly
+func (nc *NotionClient) GetStatusOptions(databaseID string) (map[string][]string, error) {
+	var db struct {
+		Properties map[string]struct {
+			Type   string `json:"type"`
+			Status struct {
+				Options []struct {
+					Name string `json:"name"`
+				} `json:"options"`
+			} `json:"status"`
+		} `json:"properties"`
+	}
+	err := nc.getDatabaseJSON(databaseID, &db)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(map[string][]string)
+	for name, property := range db.Properties {
+		if property.Type != string(notionapi.PropertyConfigStatus) {
+			continue
+		}
+		options[name] = []string{}
+		for _, option := range property.Status.Options {
+			options[name] = append(options[name], option.Name)
+		}
+	}
+	return options, nil
+}
+
+// GetPropertyIDs retrieves the ID of every property of the specified
+// database, keyed by property name. notionapi doesn't decode the IDs of every
+// type of property, so the database is fetched directly
+func (nc *NotionClient) GetPropertyIDs(databaseID string) (map[string]string, error) {
+	var db struct {
+		Properties map[string]struct {
+			ID string `json:"id"`
+		} `json:"properties"`
+	}
+	err := nc.getDatabaseJSON(databaseID, &db)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string)
+	for name, property := range db.Properties {
+		ids[name] = property.ID
+	}
+	return ids, nil
+}
+
+// getDatabaseJSON retrieves the specified database and decodes the response
+// into result, for the parts of a database notionapi doesn't decode
+func (nc *NotionClient) getDatabaseJSON(databaseID string, result interface{}) error {
+	req, err := http.NewRequestWithContext(nc.ctx, http.MethodGet, fmt.Sprintf("%s/databases/%s", notionAPIURL, databaseID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %s", err.Error())
+	}
+	req.Header.Set("Authorization", "Bearer "+nc.authToken)
+	req.Header.Set("Notion-Version", notionVersion)
+
+	resp, err := nc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get database id %s: %s", databaseID, err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %s", err.Error())
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return fmt.Errorf("failed to get database id %s: unexpected response status %s: %s", databaseID, resp.Status, apiErr.Message)
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("failed to decode database id %s: %s", databaseID, err.Error())
+	}
+	return nil
+}
+
 // GetPageByID fetches a single page by ID
 func (nc *NotionClient) GetPageByID(ctx context.Context, pageID string) (*notionapi.Page, error) {
 	if ctx == nil {
@@ -0,0 +1,99 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNoStats is returned when an app has no stats or achievements to retrieve
+var ErrNoStats = errors.New("app has no stats")
+
+// PlayerAchievements defines the response received from retrieving a user's achievements for an app
+type PlayerAchievements struct {
+	PlayerStats struct {
+		SteamID      string              `json:"steamID"`
+		GameName     string              `json:"gameName"`
+		Achievements []PlayerAchievement `json:"achievements"`
+		Success      bool                `json:"success"`
+		Error        string              `json:"error,omitempty"`
+	} `json:"playerstats"`
+}
+
+// PlayerAchievement defines whether a user has unlocked a single achievement
+type PlayerAchievement struct {
+	APIName    string      `json:"apiname"`
+	Achieved   int         `json:"achieved"`
+	UnlockTime json.Number `json:"unlocktime"`
+}
+
+// GameSchema defines the response received from retrieving the stats and achievements of an app
+type GameSchema struct {
+	Game struct {
+		GameName           string `json:"gameName"`
+		GameVersion        string `json:"gameVersion"`
+		AvailableGameStats struct {
+			Achievements []SchemaAchievement `json:"achievements"`
+		} `json:"availableGameStats"`
+	} `json:"game"`
+}
+
+// SchemaAchievement defines a single achievement available in an app
+type SchemaAchievement struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	Description  string `json:"description,omitempty"`
+	Hidden       int    `json:"hidden"`
+	Icon         string `json:"icon"`
+	IconGray     string `json:"icongray"`
+	DefaultValue int    `json:"defaultvalue"`
+}
+
+// GetPlayerAchievements returns which achievements the specified user has
+// unlocked in an app. ErrNoStats is returned for apps without achievements
+func (sc *SteamClient) GetPlayerAchievements(steamUserID string, appID string) (*PlayerAchievements, error) {
+	var achievements PlayerAchievements
+	endpoint := fmt.Sprintf("/ISteamUserStats/GetPlayerAchievements/v0001/?key=%s&steamid=%s&appid=%s&format=json", sc.steamKey, steamUserID, appID)
+	if sc.language != "" {
+		endpoint += fmt.Sprintf("&l=%s", sc.language)
+	}
+	body, err := sc.get(sc.apiURL, endpoint)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+			return nil, ErrNoStats
+		}
+		return nil, fmt.Errorf("failed to get achievements for app id %s: %s", appID, err.Error())
+	}
+
+	err = json.Unmarshal(body, &achievements)
+	if err != nil {
+		return nil, err
+	}
+	if !achievements.PlayerStats.Success {
+		return nil, ErrNoStats
+	}
+
+	return &achievements, nil
+}
+
+// GetSchemaForGame returns the achievements available in an app
+func (sc *SteamClient) GetSchemaForGame(appID string) (*GameSchema, error) {
+	var schema GameSchema
+	endpoint := fmt.Sprintf("/ISteamUserStats/GetSchemaForGame/v2/?key=%s&appid=%s&format=json", sc.steamKey, appID)
+	if sc.language != "" {
+		endpoint += fmt.Sprintf("&l=%s", sc.language)
+	}
+	body, err := sc.get(sc.apiURL, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema for app id %s: %s", appID, err.Error())
+	}
+
+	err = json.Unmarshal(body, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
@@ -0,0 +1,203 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAppCacheTTL           = 7 * 24 * time.Hour
+	defaultUnreleasedAppCacheTTL = 24 * time.Hour
+)
+
+// AppCache stores app details keyed by app ID. Implementations must be safe
+// for concurrent use
+type AppCache interface {
+	// Get returns the cached app and true, or false when the app is missing or expired
+	Get(appID string) (*SteamApp, bool)
+	// Set caches an app until ttl has passed
+	Set(appID string, app *SteamApp, ttl time.Duration) error
+	// Delete removes an app from the cache
+	Delete(appID string) error
+}
+
+// appCacheEntry is a cached app along with when it expires
+type appCacheEntry struct {
+	App       *SteamApp `json:"app"`
+	StoredAt  time.Time `json:"storedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// MemoryAppCache is an AppCache held in memory for the life of the process
+type MemoryAppCache struct {
+	mu      sync.RWMutex
+	entries map[string]appCacheEntry
+}
+
+// FileAppCache is an AppCache persisted as one JSON file per app in a directory
+type FileAppCache struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewMemoryAppCache creates an empty in-memory app cache
+func NewMemoryAppCache() *MemoryAppCache {
+	return &MemoryAppCache{entries: make(map[string]appCacheEntry)}
+}
+
+// Get returns the cached app and true, or false when the app is missing or expired
+func (c *MemoryAppCache) Get(appID string) (*SteamApp, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[appID]
+	if !ok || time.Now().After(entry.ExpiresAt) {
+		return nil, false
+	}
+	return entry.App, true
+}
+
+// Set caches an app until ttl has passed
+func (c *MemoryAppCache) Set(appID string, app *SteamApp, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.entries[appID] = appCacheEntry{App: app, StoredAt: now, ExpiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes an app from the cache
+func (c *MemoryAppCache) Delete(appID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, appID)
+	return nil
+}
+
+// NewFileAppCache creates an app cache stored in dir, creating it if needed
+func NewFileAppCache(dir string) (*FileAppCache, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("empty app cache directory provided")
+	}
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create app cache directory %s: %s", dir, err.Error())
+	}
+	return &FileAppCache{dir: dir}, nil
+}
+
+// Get returns the cached app and true, or false when the app is missing,
+// expired or unreadable
+func (c *FileAppCache) Get(appID string) (*SteamApp, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entry appCacheEntry
+	fileContent, err := os.ReadFile(c.path(appID))
+	if err != nil {
+		return nil, false
+	}
+	err = json.Unmarshal(fileContent, &entry)
+	if err != nil || entry.App == nil || time.Now().After(entry.ExpiresAt) {
+		return nil, false
+	}
+	return entry.App, true
+}
+
+// Set caches an app until ttl has passed
+func (c *FileAppCache) Set(appID string, app *SteamApp, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	fileContent, err := json.Marshal(appCacheEntry{App: app, StoredAt: now, ExpiresAt: now.Add(ttl)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal app id %s: %s", appID, err.Error())
+	}
+	tmp := c.path(appID) + ".tmp"
+	err = os.WriteFile(tmp, fileContent, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write app id %s to cache: %s", appID, err.Error())
+	}
+	return os.Rename(tmp, c.path(appID))
+}
+
+// Delete removes an app from the cache
+func (c *FileAppCache) Delete(appID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := os.Remove(c.path(appID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove app id %s from cache: %s", appID, err.Error())
+	}
+	return nil
+}
+
+func (c *FileAppCache) path(appID string) string {
+	return filepath.Join(c.dir, filepath.Base(appID)+".json")
+}
+
+// WithAppCache caches app details retrieved by GetApp in cache
+func WithAppCache(cache AppCache) ClientOption {
+	return func(sc *SteamClient) {
+		sc.appCache = cache
+	}
+}
+
+// WithCacheErrorHandler sets a function called when fetched data can't be
+// written to the app list or app details cache. The data is still returned,
+// so these failures only cost a request next time and are otherwise ignored
+func WithCacheErrorHandler(handler func(err error)) ClientOption {
+	return func(sc *SteamClient) {
+		sc.cacheErrorHandler = handler
+	}
+}
+
+// WithAppCacheTTL sets how long app details are cached. Unreleased apps use
+// their own, typically shorter, TTL since their details change as launch nears
+func WithAppCacheTTL(released time.Duration, unreleased time.Duration) ClientOption {
+	return func(sc *SteamClient) {
+		if released > 0 {
+			sc.appCacheTTL = released
+		}
+		if unreleased > 0 {
+			sc.unreleasedAppCacheTTL = unreleased
+		}
+	}
+}
+
+// InvalidateApp removes an app from the app details cache so the next GetApp refetches it
+func (sc *SteamClient) InvalidateApp(appID string) error {
+	if sc.appCache == nil {
+		return nil
+	}
+	return sc.appCache.Delete(sc.appCacheKey(appID))
+}
+
+// appCacheKey keys cached apps by locale as well as ID since prices and
+// descriptions differ between them
+func (sc *SteamClient) appCacheKey(appID string) string {
+	if sc.countryCode == "" && sc.language == "" {
+		return appID
+	}
+	return fmt.Sprintf("%s-%s-%s", appID, sc.countryCode, sc.language)
+}
+
+// appCacheTTLFor returns how long app should stay cached
+func (sc *SteamClient) appCacheTTLFor(app *SteamApp) time.Duration {
+	if app.Data.ReleaseDate.ComingSoon {
+		return sc.unreleasedAppCacheTTL
+	}
+	return sc.appCacheTTL
+}
+
+// cacheError reports a failed cache write to the configured handler, if any
+func (sc *SteamClient) cacheError(err error) {
+	if sc.cacheErrorHandler != nil {
+		sc.cacheErrorHandler(err)
+	}
+}
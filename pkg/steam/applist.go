@@ -0,0 +1,428 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	defaultAppListTTL = 24 * time.Hour
+	// appListRetryDelay is how long a stale app list is used after a failed
+	// refresh before the download is tried again
+	appListRetryDelay = 15 * time.Minute
+	minAppMatchScore  = 0.8
+)
+
+var (
+	// romanNumerals maps roman numeral tokens to their arabic equivalent so
+	// "Grand Theft Auto V" and "Grand Theft Auto 5" normalize the same way.
+	// "i" is left alone since it's far more often a word than a numeral
+	romanNumerals = map[string]string{
+		"ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6", "vii": "7",
+		"viii": "8", "ix": "9", "x": "10", "xi": "11", "xii": "12", "xiii": "13",
+		"xiv": "14", "xv": "15", "xvi": "16", "xvii": "17", "xviii": "18",
+		"xix": "19", "xx": "20",
+	}
+
+	// editionSuffixes are trailing token sequences stripped from normalized names
+	editionSuffixes = [][]string{
+		{"game", "of", "the", "year", "edition"},
+		{"game", "of", "the", "year"},
+		{"goty", "edition"},
+		{"goty"},
+		{"directors", "cut"},
+	}
+)
+
+// AppListEntry is a single app returned by ISteamApps/GetAppList
+type AppListEntry struct {
+	AppID int    `json:"appid"`
+	Name  string `json:"name"`
+}
+
+// AppMatch is a candidate app returned by a fuzzy name search, scored from 0 to 1
+type AppMatch struct {
+	AppID int     `json:"appid"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// appListFile is the on-disk format of the cached app list
+type appListFile struct {
+	FetchedAt time.Time      `json:"fetchedAt"`
+	Apps      []AppListEntry `json:"apps"`
+}
+
+// appIndex is an in-memory index of the app list keyed by normalized name and token
+type appIndex struct {
+	fetchedAt  time.Time
+	apps       []AppListEntry
+	normalized []string
+	tokens     [][]string
+	exact      map[string][]int
+	postings   map[string][]int
+}
+
+// appListCache holds the app list settings and the loaded index for a SteamClient
+type appListCache struct {
+	mu         sync.Mutex
+	path       string
+	ttl        time.Duration
+	index      *appIndex
+	retryAfter time.Time // a stale index is used until then after a failed refresh
+}
+
+// WithAppListCache persists the Steam app list to path and reuses it until it
+// is older than ttl. An empty path keeps the app list in memory only
+func WithAppListCache(path string, ttl time.Duration) ClientOption {
+	return func(sc *SteamClient) {
+		sc.appList.path = strings.TrimSpace(path)
+		if ttl > 0 {
+			sc.appList.ttl = ttl
+		}
+	}
+}
+
+// GetAppList returns every app known to Steam, using the cached list when it is still fresh
+func (sc *SteamClient) GetAppList() ([]AppListEntry, error) {
+	index, err := sc.loadAppIndex(false)
+	if err != nil {
+		return nil, err
+	}
+	return index.apps, nil
+}
+
+// RefreshAppList discards the cached app list and downloads a fresh copy
+func (sc *SteamClient) RefreshAppList() error {
+	_, err := sc.loadAppIndex(true)
+	return err
+}
+
+// SearchApps returns up to limit apps whose names best match appName, ordered
+// by descending score. A limit of 0 or less returns every candidate
+func (sc *SteamClient) SearchApps(appName string, limit int) ([]AppMatch, error) {
+	index, err := sc.loadAppIndex(false)
+	if err != nil {
+		return nil, err
+	}
+	return index.search(appName, limit), nil
+}
+
+// loadAppIndex returns the in-memory app index, loading it from disk or
+// downloading it from Steam when missing or older than the configured TTL
+func (sc *SteamClient) loadAppIndex(force bool) (*appIndex, error) {
+	cache := &sc.appList
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	ttl := cache.ttl
+	if ttl <= 0 {
+		ttl = defaultAppListTTL
+	}
+
+	if !force && cache.index != nil && (time.Since(cache.index.fetchedAt) < ttl || time.Now().Before(cache.retryAfter)) {
+		return cache.index, nil
+	}
+
+	var stale *appListFile
+	if !force && cache.path != "" {
+		cached, err := readAppListFile(cache.path)
+		if err == nil {
+			if time.Since(cached.FetchedAt) < ttl {
+				cache.index = newAppIndex(cached)
+				return cache.index, nil
+			}
+			stale = cached
+		}
+	}
+
+	fetched, err := sc.fetchAppList()
+	if err != nil {
+		if stale != nil { // better to match against an old list than not at all
+			cache.index = newAppIndex(stale)
+			cache.retryAfter = time.Now().Add(appListRetryDelay)
+			return cache.index, nil
+		}
+		return nil, err
+	}
+
+	if cache.path != "" {
+		// the list was fetched, so a cache that can't be written only costs
+		// a download next time
+		err = writeAppListFile(cache.path, fetched)
+		if err != nil {
+			sc.cacheError(fmt.Errorf("failed to write app list cache %s: %s", cache.path, err.Error()))
+		}
+	}
+
+	cache.index = newAppIndex(fetched)
+	cache.retryAfter = time.Time{}
+	return cache.index, nil
+}
+
+// fetchAppList downloads the full app list from the Steam Web API
+func (sc *SteamClient) fetchAppList() (*appListFile, error) {
+	var allApps struct {
+		Applist struct {
+			Apps []AppListEntry `json:"apps"`
+		} `json:"applist"`
+	}
+	endpoint := fmt.Sprintf("/ISteamApps/GetAppList/v0002/?key=%s&format=json", sc.steamKey)
+	body, err := sc.get(sc.apiURL, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve all steam apps: %s", err.Error())
+	}
+
+	err = json.Unmarshal(body, &allApps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal app list: %s", err.Error())
+	}
+
+	return &appListFile{
+		FetchedAt: time.Now(),
+		Apps:      allApps.Applist.Apps,
+	}, nil
+}
+
+func readAppListFile(path string) (*appListFile, error) {
+	var cached appListFile
+	fileContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(fileContent, &cached)
+	if err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+func writeAppListFile(path string, list *appListFile) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	fileContent, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, fileContent, 0o644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// newAppIndex builds the normalized name and token index for an app list
+func newAppIndex(list *appListFile) *appIndex {
+	index := appIndex{
+		fetchedAt:  list.FetchedAt,
+		apps:       list.Apps,
+		normalized: make([]string, len(list.Apps)),
+		tokens:     make([][]string, len(list.Apps)),
+		exact:      make(map[string][]int),
+		postings:   make(map[string][]int),
+	}
+	for i, app := range list.Apps {
+		tokens := normalizeTokens(app.Name)
+		normalized := strings.Join(tokens, " ")
+		index.normalized[i] = normalized
+		index.tokens[i] = tokens
+		if normalized == "" {
+			continue
+		}
+		index.exact[normalized] = append(index.exact[normalized], i)
+		for _, token := range uniqueTokens(tokens) {
+			index.postings[token] = append(index.postings[token], i)
+		}
+	}
+	return &index
+}
+
+// search ranks indexed apps against appName
+func (index *appIndex) search(appName string, limit int) []AppMatch {
+	queryTokens := normalizeTokens(appName)
+	query := strings.Join(queryTokens, " ")
+	if query == "" {
+		return nil
+	}
+	lowered := cleanName(appName)
+
+	// Only score apps sharing at least half of the query's tokens; scoring the
+	// whole list with edit distance is far too slow
+	unique := uniqueTokens(queryTokens)
+	required := (len(unique) + 1) / 2
+	shared := make(map[int]int)
+	for _, token := range unique {
+		for _, i := range index.postings[token] {
+			shared[i]++
+		}
+	}
+
+	var matches []AppMatch
+	for i, count := range shared {
+		if count < required {
+			continue
+		}
+		var score float64
+		if index.normalized[i] == query {
+			score = 0.97
+			if cleanName(index.apps[i].Name) == lowered {
+				score = 1
+			}
+		} else {
+			score = 0.95 * (0.5*diceCoefficient(queryTokens, index.tokens[i]) +
+				0.5*levenshteinRatio(query, index.normalized[i]))
+		}
+		matches = append(matches, AppMatch{
+			AppID: index.apps[i].AppID,
+			Name:  index.apps[i].Name,
+			Score: score,
+		})
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		if len(matches[a].Name) != len(matches[b].Name) {
+			return len(matches[a].Name) < len(matches[b].Name)
+		}
+		return matches[a].AppID < matches[b].AppID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// cleanName lowercases and unescapes a name without any further normalization
+func cleanName(name string) string {
+	return html.UnescapeString(strings.TrimSpace(strings.ToLower(name)))
+}
+
+// NormalizeAppName reduces an app name to the form used for matching: trademark
+// symbols, punctuation and edition suffixes are dropped and roman numerals are
+// converted to arabic numbers
+func NormalizeAppName(name string) string {
+	return strings.Join(normalizeTokens(name), " ")
+}
+
+func normalizeTokens(name string) []string {
+	cleaned := cleanName(name)
+	replacer := strings.NewReplacer(
+		"™", "", "®", "", "©", "", "(tm)", "", "(r)", "",
+		"'", "", "’", "", "&", " and ",
+	)
+	cleaned = replacer.Replace(cleaned)
+
+	tokens := strings.FieldsFunc(cleaned, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, token := range tokens {
+		if numeral, ok := romanNumerals[token]; ok {
+			tokens[i] = numeral
+		}
+	}
+	return stripEditionSuffix(tokens)
+}
+
+// stripEditionSuffix drops a trailing "<word> edition" or a known suffix such as "goty"
+func stripEditionSuffix(tokens []string) []string {
+	for _, suffix := range editionSuffixes {
+		if len(tokens) > len(suffix) && hasTokenSuffix(tokens, suffix) {
+			return tokens[:len(tokens)-len(suffix)]
+		}
+	}
+	if len(tokens) > 2 && tokens[len(tokens)-1] == "edition" {
+		return tokens[:len(tokens)-2]
+	}
+	return tokens
+}
+
+func hasTokenSuffix(tokens []string, suffix []string) bool {
+	offset := len(tokens) - len(suffix)
+	for i, token := range suffix {
+		if tokens[offset+i] != token {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	var unique []string
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
+
+// diceCoefficient measures token overlap between two names from 0 to 1
+func diceCoefficient(a []string, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(a))
+	for _, token := range a {
+		counts[token]++
+	}
+	shared := 0
+	for _, token := range b {
+		if counts[token] > 0 {
+			counts[token]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+// levenshteinRatio converts the edit distance between two strings into a similarity from 0 to 1
+func levenshteinRatio(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
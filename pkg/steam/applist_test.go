@@ -0,0 +1,266 @@
+package steam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeAppName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Portal 2", want: "portal 2"},
+		{name: "  Portal 2  ", want: "portal 2"},
+		{name: "Grand Theft Auto V", want: "grand theft auto 5"},
+		{name: "DARK SOULS™ III", want: "dark souls 3"},
+		{name: "The Witcher® 3: Wild Hunt", want: "the witcher 3 wild hunt"},
+		{name: "Sid Meier's Civilization® VI", want: "sid meiers civilization 6"},
+		{name: "Tom Clancy&#39;s Rainbow Six® Siege", want: "tom clancys rainbow six siege"},
+		{name: "Ratchet & Clank", want: "ratchet and clank"},
+		{name: "Half-Life 2", want: "half life 2"},
+		{name: "I Am Bread", want: "i am bread"},
+		{name: "Batman: Arkham City - Game of the Year Edition", want: "batman arkham city"},
+		{name: "Fallout 3: Game of the Year", want: "fallout 3"},
+		{name: "Borderlands GOTY", want: "borderlands"},
+		{name: "DEATH STRANDING DIRECTOR'S CUT", want: "death stranding"},
+		{name: "Cyberpunk 2077 Ultimate Edition", want: "cyberpunk 2077"},
+		{name: "GOTY", want: "goty"},
+		{name: "Collector's Edition", want: "collectors edition"},
+		{name: "™", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeAppName(tt.name); got != tt.want {
+				t.Errorf("NormalizeAppName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppIndexSearch(t *testing.T) {
+	index := newAppIndex(&appListFile{Apps: []AppListEntry{
+		{AppID: 400, Name: "Portal"},
+		{AppID: 620, Name: "Portal 2"},
+		{AppID: 621, Name: "Portal 2 - The Final Hours"},
+		{AppID: 271590, Name: "Grand Theft Auto V"},
+		{AppID: 12210, Name: "Grand Theft Auto IV: Complete Edition"},
+		{AppID: 292030, Name: "The Witcher® 3: Wild Hunt"},
+		{AppID: 1000, Name: "Duplicate"},
+		{AppID: 999, Name: "Duplicate"},
+		{AppID: 1, Name: "™"},
+	}})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantID   int
+		minScore float64
+		maxScore float64
+	}{
+		{name: "exact name", query: "Portal 2", wantID: 620, minScore: 1, maxScore: 1},
+		{name: "exact name in another case", query: "portal 2", wantID: 620, minScore: 1, maxScore: 1},
+		{name: "same normalized name", query: "Grand Theft Auto 5", wantID: 271590, minScore: 0.97, maxScore: 0.97},
+		{name: "trademark symbols ignored", query: "The Witcher 3: Wild Hunt", wantID: 292030, minScore: 0.97, maxScore: 0.97},
+		{name: "edition suffix ignored", query: "Grand Theft Auto IV", wantID: 12210, minScore: 0.97, maxScore: 0.97},
+		{name: "small typo still matches", query: "Grand Theft Auto VI", wantID: 271590, minScore: minAppMatchScore, maxScore: 0.97},
+		{name: "partial name stays below the match threshold", query: "The Witcher 3", wantID: 292030, minScore: 0, maxScore: minAppMatchScore},
+		{name: "ties prefer the lowest app ID", query: "Duplicate", wantID: 999, minScore: 1, maxScore: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := index.search(tt.query, 0)
+			if len(matches) == 0 {
+				t.Fatalf("search(%q) found no matches, want %d", tt.query, tt.wantID)
+			}
+			got := matches[0]
+			if got.AppID != tt.wantID {
+				t.Errorf("search(%q) best match = %d %q, want %d", tt.query, got.AppID, got.Name, tt.wantID)
+			}
+			if got.Score < tt.minScore || got.Score > tt.maxScore {
+				t.Errorf("search(%q) score = %f, want between %f and %f", tt.query, got.Score, tt.minScore, tt.maxScore)
+			}
+			for i := 1; i < len(matches); i++ {
+				if matches[i].Score > matches[i-1].Score {
+					t.Errorf("search(%q) matches aren't ordered by score: %v", tt.query, matches)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestAppIndexSearchCandidates(t *testing.T) {
+	index := newAppIndex(&appListFile{Apps: []AppListEntry{
+		{AppID: 400, Name: "Portal"},
+		{AppID: 620, Name: "Portal 2"},
+		{AppID: 621, Name: "Portal 2 - The Final Hours"},
+	}})
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []int
+	}{
+		{name: "every candidate", query: "Portal 2", limit: 0, want: []int{620, 400, 621}},
+		{name: "limited", query: "Portal 2", limit: 2, want: []int{620, 400}},
+		{name: "no shared tokens", query: "Stardew Valley", limit: 0, want: nil},
+		{name: "empty once normalized", query: "™", limit: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, match := range index.search(tt.query, tt.limit) {
+				got = append(got, match.AppID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAppIndex(t *testing.T) {
+	const fetchedList = `{"applist":{"apps":[{"appid":620,"name":"Portal 2"}]}}`
+	cachedApps := []AppListEntry{{AppID: 400, Name: "Portal"}}
+	fetchedApps := []AppListEntry{{AppID: 620, Name: "Portal 2"}}
+
+	tests := []struct {
+		name         string
+		cachedAge    time.Duration // age of the list on disk, 0 for none
+		unwritable   bool
+		serverFails  bool
+		want         []AppListEntry
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "fresh cache is used", cachedAge: time.Hour, want: cachedApps, wantRequests: 0},
+		{name: "stale cache is refreshed", cachedAge: 48 * time.Hour, want: fetchedApps, wantRequests: 1},
+		{name: "stale cache is used when steam fails", cachedAge: 48 * time.Hour, serverFails: true, want: cachedApps, wantRequests: 1},
+		{name: "missing cache is fetched", want: fetchedApps, wantRequests: 1},
+		{name: "missing cache fails when steam fails", serverFails: true, wantRequests: 1, wantErr: true},
+		{name: "unwritable cache keeps the fetched list", unwritable: true, want: fetchedApps, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.serverFails {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				_, _ = w.Write([]byte(fetchedList))
+			}))
+			defer server.Close()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "applist.json")
+			if tt.cachedAge > 0 {
+				err := writeAppListFile(path, &appListFile{FetchedAt: time.Now().Add(-tt.cachedAge), Apps: cachedApps})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.unwritable {
+				// a file where the cache directory should be can't be written through
+				blocker := filepath.Join(dir, "blocker")
+				err := os.WriteFile(blocker, nil, 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				path = filepath.Join(blocker, "applist.json")
+			}
+
+			var cacheErrs []error
+			sc, err := NewClient(context.Background(), "key",
+				WithAPIURL(server.URL),
+				WithAppListCache(path, 24*time.Hour),
+				WithCacheErrorHandler(func(err error) { cacheErrs = append(cacheErrs, err) }),
+			)
+			if err != nil {
+				t.Fatalf("NewClient() error = %s", err.Error())
+			}
+
+			got, err := sc.GetAppList()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAppList() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAppList() = %v, want %v", got, tt.want)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("server saw %d requests, want %d", requests, tt.wantRequests)
+			}
+			if (len(cacheErrs) != 0) != tt.unwritable {
+				t.Errorf("cache errors = %v, want some %t", cacheErrs, tt.unwritable)
+			}
+
+			// A loaded list is kept in memory until it is stale, and a stale
+			// fallback isn't refreshed again until the retry delay has passed
+			if !tt.wantErr {
+				_, err = sc.GetAppList()
+				if err != nil {
+					t.Fatalf("GetAppList() error = %s", err.Error())
+				}
+				if requests != tt.wantRequests {
+					t.Errorf("second GetAppList() made %d requests, want none", requests-tt.wantRequests)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadAppIndexRetriesAfterDelay(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "applist.json")
+	err := writeAppListFile(path, &appListFile{FetchedAt: time.Now().Add(-48 * time.Hour), Apps: []AppListEntry{{AppID: 400, Name: "Portal"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := NewClient(context.Background(), "key",
+		WithAPIURL(server.URL),
+		WithAppListCache(path, 24*time.Hour),
+		WithMaxRetries(0),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %s", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = sc.SearchApps("Portal", 1)
+		if err != nil {
+			t.Fatalf("SearchApps() error = %s", err.Error())
+		}
+	}
+	if requests != 1 {
+		t.Errorf("server saw %d requests while the stale list was in use, want 1", requests)
+	}
+
+	// Once the delay passes the refresh is tried again
+	sc.appList.retryAfter = time.Now().Add(-time.Second)
+	_, err = sc.SearchApps("Portal", 1)
+	if err != nil {
+		t.Fatalf("SearchApps() error = %s", err.Error())
+	}
+	if requests != 2 {
+		t.Errorf("server saw %d requests after the retry delay, want 2", requests)
+	}
+}
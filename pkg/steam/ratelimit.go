@@ -0,0 +1,154 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// The store allows roughly 200 requests every 5 minutes before it starts
+	// answering with 429s
+	defaultStoreRequests = 200
+	defaultStorePeriod   = 5 * time.Minute
+	defaultStoreBurst    = 10
+
+	defaultMaxRetries = 3
+	baseRetryDelay    = 2 * time.Second
+	maxRetryDelay     = 2 * time.Minute
+)
+
+// RateLimiter is a token bucket limiting how quickly requests are made. A
+// single RateLimiter may be shared between clients and goroutines
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// StatusError is returned when Steam responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %s", e.Status)
+}
+
+// NewRateLimiter creates a limiter allowing requests per period, with up to
+// burst requests made back to back. Each value is raised to at least 1
+// request, 1 second and 1 burst so the rate is always positive and finite
+func NewRateLimiter(requests int, per time.Duration, burst int) *RateLimiter {
+	if requests < 1 {
+		requests = 1
+	}
+	if per <= 0 {
+		per = time.Second
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   float64(requests) / per.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		rl.mu.Lock()
+		now := time.Now()
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+		if rl.tokens > rl.burst {
+			rl.tokens = rl.burst
+		}
+		rl.last = now
+		if rl.tokens >= 1 {
+			rl.tokens--
+			rl.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+		rl.mu.Unlock()
+
+		err := sleep(ctx, wait)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WithRateLimiter overrides the limiter applied to Steam Store requests. A
+// nil limiter disables rate limiting
+func WithRateLimiter(rl *RateLimiter) ClientOption {
+	return func(sc *SteamClient) {
+		sc.storeLimiter = rl
+	}
+}
+
+// WithMaxRetries sets how many times a request is retried after a 429 or 503 response
+func WithMaxRetries(retries int) ClientOption {
+	return func(sc *SteamClient) {
+		if retries >= 0 {
+			sc.maxRetries = retries
+		}
+	}
+}
+
+// retryDelay returns how long to wait before retrying after a throttled
+// response, preferring the server's Retry-After over exponential backoff.
+// Either is capped at maxRetryDelay so one bad header can't stall a sync
+func retryDelay(statusErr *StatusError, attempt int) time.Duration {
+	delay := statusErr.RetryAfter
+	if delay <= 0 {
+		delay = baseRetryDelay << attempt
+	}
+	if delay <= 0 || delay > maxRetryDelay { // a large attempt overflows the shift
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// isRetryable reports whether a response status means the request should be retried
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
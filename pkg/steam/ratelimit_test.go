@@ -0,0 +1,60 @@
+package steam
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		requests  int
+		per       time.Duration
+		burst     int
+		wantRate  float64
+		wantBurst float64
+	}{
+		{name: "store defaults", requests: defaultStoreRequests, per: defaultStorePeriod, burst: defaultStoreBurst, wantRate: 200.0 / 300, wantBurst: 10},
+		{name: "zero requests", requests: 0, per: time.Minute, burst: 1, wantRate: 1.0 / 60, wantBurst: 1},
+		{name: "negative requests", requests: -5, per: time.Second, burst: 1, wantRate: 1, wantBurst: 1},
+		{name: "zero period", requests: 10, per: 0, burst: 1, wantRate: 10, wantBurst: 1},
+		{name: "negative period", requests: 10, per: -time.Minute, burst: 1, wantRate: 10, wantBurst: 1},
+		{name: "zero burst", requests: 1, per: time.Second, burst: 0, wantRate: 1, wantBurst: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := NewRateLimiter(tt.requests, tt.per, tt.burst)
+			if math.Abs(rl.rate-tt.wantRate) > 1e-9 || rl.burst != tt.wantBurst {
+				t.Errorf("NewRateLimiter() rate, burst = %f, %f, want %f, %f", rl.rate, rl.burst, tt.wantRate, tt.wantBurst)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		attempt    int
+		want       time.Duration
+	}{
+		{name: "first backoff", attempt: 0, want: baseRetryDelay},
+		{name: "backoff doubles", attempt: 2, want: 4 * baseRetryDelay},
+		{name: "backoff is capped", attempt: 10, want: maxRetryDelay},
+		{name: "backoff shift overflow is capped", attempt: 70, want: maxRetryDelay},
+		{name: "retry-after is preferred", retryAfter: 30 * time.Second, attempt: 3, want: 30 * time.Second},
+		{name: "retry-after is capped", retryAfter: 6 * time.Hour, attempt: 0, want: maxRetryDelay},
+		{name: "past retry-after falls back to backoff", retryAfter: -time.Minute, attempt: 1, want: 2 * baseRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := retryDelay(&StatusError{StatusCode: 429, RetryAfter: tt.retryAfter}, tt.attempt)
+			if got != tt.want {
+				t.Errorf("retryDelay() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
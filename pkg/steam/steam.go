@@ -4,23 +4,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"html"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
 	steamAPIURL = "https://api.steampowered.com"
 	steamURL    = "https://store.steampowered.com"
+
+	defaultUserAgent = "kanbanchan"
 )
 
 // SteamClient contains authentication info for the Steam client
 type SteamClient struct {
-	ctx      context.Context
-	steamKey string
+	ctx          context.Context
+	steamKey     string
+	httpClient   *http.Client
+	apiURL       string
+	storeURL     string
+	userAgent    string
+	appList      appListCache
+	storeLimiter *RateLimiter
+	maxRetries   int
+
+	appCache              AppCache
+	appCacheTTL           time.Duration
+	unreleasedAppCacheTTL time.Duration
+	cacheErrorHandler     func(err error)
+
+	countryCode string
+	language    string
 }
 
+// ClientOption configures optional settings on a SteamClient
+type ClientOption func(*SteamClient)
+
 // WishlistApp defines the data retrieved for an app on a user's wishlist
 type WishlistApp struct {
 	ID          string      `json:"id,omitempty"`
@@ -35,42 +55,134 @@ type WishlistApp struct {
 type OwnedApps struct {
 	Response struct {
 		GameCount json.Number `json:"game_count"`
-		Games     []struct {
-			AppID                    json.Number `json:"appid"`
-			Name                     string      `json:"name"`
-			Playtime                 json.Number `json:"playtime_forever"`
-			PlaytimeWindows          json.Number `json:"playtime_windows_forever"`
-			PlaytimeMac              json.Number `json:"playtime_mac_forever"`
-			PlaytimeLinux            json.Number `json:"playtime_linux_forever"`
-			PlaytimeDisconnected     json.Number `json:"playtime_disconnected"`
-			IconURL                  string      `json:"img_icon_url"`
-			LastPlayed               json.Number `json:"rtime_last_played"`
-			HasCommunityVisibleStats bool        `json:"has_community_visible_stats,omitempty"`
-		} `json:"games"`
+		Games     []OwnedApp  `json:"games"`
+	} `json:"response"`
+}
+
+// OwnedApp defines a single app in a user's owned apps
+type OwnedApp struct {
+	AppID                    json.Number `json:"appid"`
+	Name                     string      `json:"name"`
+	Playtime                 json.Number `json:"playtime_forever"`
+	PlaytimeWindows          json.Number `json:"playtime_windows_forever"`
+	PlaytimeMac              json.Number `json:"playtime_mac_forever"`
+	PlaytimeLinux            json.Number `json:"playtime_linux_forever"`
+	PlaytimeDeck             json.Number `json:"playtime_deck_forever"`
+	PlaytimeDisconnected     json.Number `json:"playtime_disconnected"`
+	IconURL                  string      `json:"img_icon_url"`
+	LastPlayed               json.Number `json:"rtime_last_played"`
+	HasCommunityVisibleStats bool        `json:"has_community_visible_stats,omitempty"`
+}
+
+// RecentlyPlayedApps defines the response received from retrieving the apps a user played in the last two weeks
+type RecentlyPlayedApps struct {
+	Response struct {
+		TotalCount json.Number         `json:"total_count"`
+		Games      []RecentlyPlayedApp `json:"games"`
 	} `json:"response"`
 }
 
+// RecentlyPlayedApp defines a single app a user played in the last two weeks
+type RecentlyPlayedApp struct {
+	AppID           json.Number `json:"appid"`
+	Name            string      `json:"name"`
+	Playtime2Weeks  json.Number `json:"playtime_2weeks"`
+	Playtime        json.Number `json:"playtime_forever"`
+	PlaytimeWindows json.Number `json:"playtime_windows_forever"`
+	PlaytimeMac     json.Number `json:"playtime_mac_forever"`
+	PlaytimeLinux   json.Number `json:"playtime_linux_forever"`
+	IconURL         string      `json:"img_icon_url"`
+}
+
 // SteamApp defines the response received from retrieving a specific Steam app by ID
 type SteamApp struct {
-	Success bool `json:"success"`
-	Data    struct {
-		Type        string      `json:"type"`
-		Name        string      `json:"name"`
-		AppID       json.Number `json:"steam_appid"`
-		HeaderImage string      `json:"header_image"`
-		Genres      []struct {
-			ID          string `json:"id"`
-			Description string `json:"description"`
-		} `json:"genres"`
-		ReleaseDate struct {
-			Date string `json:"date"`
-		} `json:"release_date"`
-	} `json:"data"`
+	Success bool    `json:"success"`
+	Data    AppData `json:"data"`
+}
+
+// AppData defines the details of a Steam app
+type AppData struct {
+	Type             string         `json:"type"`
+	Name             string         `json:"name"`
+	AppID            json.Number    `json:"steam_appid"`
+	IsFree           bool           `json:"is_free"`
+	DLC              []int          `json:"dlc,omitempty"`
+	ShortDescription string         `json:"short_description"`
+	HeaderImage      string         `json:"header_image"`
+	Website          string         `json:"website,omitempty"`
+	Developers       []string       `json:"developers,omitempty"`
+	Publishers       []string       `json:"publishers,omitempty"`
+	PriceOverview    *PriceOverview `json:"price_overview,omitempty"`
+	Platforms        Platforms      `json:"platforms"`
+	Metacritic       *Metacritic    `json:"metacritic,omitempty"`
+	Categories       []Category     `json:"categories,omitempty"`
+	Genres           []Genre        `json:"genres"`
+	Screenshots      []Screenshot   `json:"screenshots,omitempty"`
+	ReleaseDate      ReleaseDate    `json:"release_date"`
+}
+
+// PriceOverview defines the current store price of an app in the requested country's currency
+type PriceOverview struct {
+	Currency         string `json:"currency"`
+	Initial          int    `json:"initial"`
+	Final            int    `json:"final"`
+	DiscountPercent  int    `json:"discount_percent"`
+	InitialFormatted string `json:"initial_formatted"`
+	FinalFormatted   string `json:"final_formatted"`
+}
+
+// Platforms defines which operating systems an app supports
+type Platforms struct {
+	Windows bool `json:"windows"`
+	Mac     bool `json:"mac"`
+	Linux   bool `json:"linux"`
+}
+
+// Metacritic defines an app's Metacritic score
+type Metacritic struct {
+	Score int    `json:"score"`
+	URL   string `json:"url"`
+}
+
+// Category defines a Steam store feature category such as "Single-player"
+type Category struct {
+	ID          int    `json:"id"`
+	Description string `json:"description"`
+}
+
+// Genre defines a Steam store genre
+type Genre struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// Screenshot defines an app screenshot
+type Screenshot struct {
+	ID        int    `json:"id"`
+	Thumbnail string `json:"path_thumbnail"`
+	Full      string `json:"path_full"`
+}
+
+// ReleaseDate defines when an app releases. Date is localized to the
+// requested language and may not be a precise date while ComingSoon is set
+type ReleaseDate struct {
+	ComingSoon bool   `json:"coming_soon"`
+	Date       string `json:"date"`
 }
 
 // NewClient creates a new Steam client authenticated with the supplied steam key
-func NewClient(ctx context.Context, steamKey string) (*SteamClient, error) {
-	var client SteamClient
+func NewClient(ctx context.Context, steamKey string, opts ...ClientOption) (*SteamClient, error) {
+	client := SteamClient{
+		httpClient:   http.DefaultClient,
+		apiURL:       steamAPIURL,
+		storeURL:     steamURL,
+		userAgent:    defaultUserAgent,
+		storeLimiter: NewRateLimiter(defaultStoreRequests, defaultStorePeriod, defaultStoreBurst),
+		maxRetries:   defaultMaxRetries,
+
+		appCacheTTL:           defaultAppCacheTTL,
+		unreleasedAppCacheTTL: defaultUnreleasedAppCacheTTL,
+	}
 	if ctx == nil {
 		client.ctx = context.Background()
 	} else {
@@ -81,9 +193,58 @@ func NewClient(ctx context.Context, steamKey string) (*SteamClient, error) {
 		return nil, fmt.Errorf("empty steamKey provided")
 	}
 	client.steamKey = key
+	for _, opt := range opts {
+		opt(&client)
+	}
 	return &client, nil
 }
 
+// WithHTTPClient overrides the http.Client used for all requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(sc *SteamClient) {
+		if httpClient != nil {
+			sc.httpClient = httpClient
+		}
+	}
+}
+
+// WithAPIURL overrides the base URL of the Steam Web API (api.steampowered.com)
+func WithAPIURL(apiURL string) ClientOption {
+	return func(sc *SteamClient) {
+		if apiURL = strings.TrimRight(strings.TrimSpace(apiURL), "/"); apiURL != "" {
+			sc.apiURL = apiURL
+		}
+	}
+}
+
+// WithStoreURL overrides the base URL of the Steam Store (store.steampowered.com)
+func WithStoreURL(storeURL string) ClientOption {
+	return func(sc *SteamClient) {
+		if storeURL = strings.TrimRight(strings.TrimSpace(storeURL), "/"); storeURL != "" {
+			sc.storeURL = storeURL
+		}
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(sc *SteamClient) {
+		if userAgent = strings.TrimSpace(userAgent); userAgent != "" {
+			sc.userAgent = userAgent
+		}
+	}
+}
+
+// WithLocale requests app details for a country code (e.g. "us"), which sets
+// the price currency, and a language (e.g. "english"), which localizes
+// names, descriptions and release dates
+func WithLocale(countryCode string, language string) ClientOption {
+	return func(sc *SteamClient) {
+		sc.countryCode = strings.ToLower(strings.TrimSpace(countryCode))
+		sc.language = strings.ToLower(strings.TrimSpace(language))
+	}
+}
+
 // GetUserWishlist returns a list of apps on the specified user's wishlist
 func (sc *SteamClient) GetUserWishlist(steamUserID string) ([]WishlistApp, error) {
 	var wishlist []WishlistApp
@@ -91,16 +252,10 @@ func (sc *SteamClient) GetUserWishlist(steamUserID string) ([]WishlistApp, error
 	for {
 		var wishlistPage map[string]WishlistApp
 		endpoint := fmt.Sprintf("/wishlist/profiles/%s/wishlistdata/?p=%d", steamUserID, i)
-		resp, err := http.Get(fmt.Sprintf("%s%s", steamURL, endpoint))
+		body, err := sc.get(sc.storeURL, endpoint)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make http request to get wishlist for user id %s: %s", steamUserID, err.Error())
 		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %s", err.Error())
-		}
 
 		if string(body) == "[]" { // no entries returned
 			break
@@ -108,7 +263,6 @@ func (sc *SteamClient) GetUserWishlist(steamUserID string) ([]WishlistApp, error
 
 		err = json.Unmarshal(body, &wishlistPage)
 		if err != nil {
-			fmt.Println(string(body), resp.Status, resp.StatusCode)
 			return nil, fmt.Errorf("failed to unmarshal response body: %s", err.Error())
 		}
 
@@ -129,34 +283,59 @@ func (sc *SteamClient) GetUserOwnedGames(steamUserID string) (*OwnedApps, error)
 	// Optional URL Params: &skip_unvetted_apps=false | &include_played_free_games=1 | &include_appinfo=1
 	var ownedApps OwnedApps
 	endpoint := fmt.Sprintf("/IPlayerService/GetOwnedGames/v0001/?key=%s&steamid=%s&include_appinfo=1&include_played_free_games=1&skip_unvetted_apps=false&format=json", sc.steamKey, steamUserID)
-	resp, err := http.Get(fmt.Sprintf("%s%s", steamAPIURL, endpoint))
+	body, err := sc.get(sc.apiURL, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	err = json.Unmarshal(body, &ownedApps)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(body, &ownedApps)
+	return &ownedApps, nil
+}
+
+// GetRecentlyPlayedGames returns the apps the specified user played in the
+// last two weeks. A count of 0 returns every recently played app
+func (sc *SteamClient) GetRecentlyPlayedGames(steamUserID string, count int) (*RecentlyPlayedApps, error) {
+	var recentApps RecentlyPlayedApps
+	endpoint := fmt.Sprintf("/IPlayerService/GetRecentlyPlayedGames/v0001/?key=%s&steamid=%s&format=json", sc.steamKey, steamUserID)
+	if count > 0 {
+		endpoint += fmt.Sprintf("&count=%d", count)
+	}
+	body, err := sc.get(sc.apiURL, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ownedApps, nil
+	err = json.Unmarshal(body, &recentApps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recentApps, nil
 }
 
-// GetApp retrieves steam app information for the specified app ID
+// GetApp retrieves steam app information for the specified app ID, using the
+// app details cache when one is configured
 func (sc *SteamClient) GetApp(appID string) (*SteamApp, error) {
+	cacheKey := sc.appCacheKey(appID)
+	if sc.appCache != nil {
+		if cached, ok := sc.appCache.Get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
 	var app map[string]SteamApp
 	endpoint := fmt.Sprintf("/api/appdetails?appids=%s", appID)
-	resp, err := http.Get(fmt.Sprintf("%s%s", steamURL, endpoint))
-	if err != nil {
-		return nil, err
+	if sc.countryCode != "" {
+		endpoint += fmt.Sprintf("&cc=%s", sc.countryCode)
 	}
-
-	body, err := io.ReadAll(resp.Body)
+	if sc.language != "" {
+		endpoint += fmt.Sprintf("&l=%s", sc.language)
+	}
+	body, err := sc.get(sc.storeURL, endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -167,55 +346,90 @@ func (sc *SteamClient) GetApp(appID string) (*SteamApp, error) {
 	}
 
 	steamApp := app[appID]
+	if sc.appCache != nil && steamApp.Success {
+		// the details were fetched, so a cache that can't be written only
+		// costs a request next time
+		err = sc.appCache.Set(cacheKey, &steamApp, sc.appCacheTTLFor(&steamApp))
+		if err != nil {
+			sc.cacheError(fmt.Errorf("failed to cache steam app id %s: %s", appID, err.Error()))
+		}
+	}
 	return &steamApp, nil
 }
 
-// GetAppByName gets a Steam App
+// GetAppByName gets the Steam App whose name best matches appName. Names are
+// compared after normalization, so trademark symbols, punctuation, roman
+// numerals and edition suffixes don't prevent a match
 func (sc *SteamClient) GetAppByName(appName string) (*SteamApp, error) {
-	var allApps struct {
-		Applist struct {
-			Apps []struct {
-				Appid int    `json:"appid"`
-				Name  string `json:"name"`
-			} `json:"apps"`
-		} `json:"applist"`
-	}
-	endpoint := fmt.Sprintf("/ISteamApps/GetAppList/v0002/?key=%s&format=json", sc.steamKey)
-	resp, err := http.Get(fmt.Sprintf("%s%s", steamAPIURL, endpoint))
+	matches, err := sc.SearchApps(appName, 1)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve all steam apps: %s", err.Error())
+		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if len(matches) == 0 || matches[0].Score < minAppMatchScore {
+		return nil, fmt.Errorf("failed to find steam app \"%s\"", appName)
 	}
 
-	err = json.Unmarshal(body, &allApps)
+	foundID := matches[0].AppID
+	app, err := sc.GetApp(fmt.Sprintf("%d", foundID))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get steam app by id %d: %s", foundID, err.Error())
 	}
 
-	found := false
-	foundID := 0
-	cleanedSearch := html.UnescapeString(strings.TrimSpace(strings.ToLower(appName)))
-	for _, app := range allApps.Applist.Apps {
-		cleanedApp := html.UnescapeString(strings.TrimSpace(strings.ToLower(app.Name)))
-		if cleanedSearch == cleanedApp {
-			found = true
-			foundID = app.Appid
-			break
+	return app, nil
+}
+
+// get performs a GET request against baseURL+endpoint using the client's
+// http.Client and context, returning the response body. Store requests are
+// rate limited and throttled responses are retried after a backoff
+func (sc *SteamClient) get(baseURL string, endpoint string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if baseURL == sc.storeURL && sc.storeLimiter != nil {
+			err := sc.storeLimiter.Wait(sc.ctx)
+			if err != nil {
+				return nil, err
+			}
 		}
+
+		body, err := sc.doGet(baseURL, endpoint)
+		statusErr, ok := err.(*StatusError)
+		if !ok || !isRetryable(statusErr.StatusCode) || attempt >= sc.maxRetries {
+			return body, err
+		}
+
+		err = sleep(sc.ctx, retryDelay(statusErr, attempt))
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doGet performs a single GET request
+func (sc *SteamClient) doGet(baseURL string, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(sc.ctx, http.MethodGet, fmt.Sprintf("%s%s", baseURL, endpoint), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %s", err.Error())
 	}
+	req.Header.Set("User-Agent", sc.userAgent)
 
-	if !found {
-		return nil, fmt.Errorf("failed to find steam app \"%s\"", appName)
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	app, err := sc.GetApp(fmt.Sprintf("%d", foundID))
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get steam app by id %d: %s", foundID, err.Error())
+		return nil, fmt.Errorf("failed to read response body: %s", err.Error())
 	}
 
-	return app, nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return body, nil
 }
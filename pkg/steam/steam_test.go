@@ -0,0 +1,186 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// request is what a fake Steam server saw for a single request
+type request struct {
+	URI       string
+	UserAgent string
+}
+
+// fakeSteam starts a server answering every request with body and records
+// the requests it receives
+func fakeSteam(t *testing.T, body string) (*httptest.Server, func() []request) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, request{URI: r.URL.RequestURI(), UserAgent: r.UserAgent()})
+		mu.Unlock()
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]request(nil), requests...)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	api, apiRequests := fakeSteam(t, "ok")
+	store, storeRequests := fakeSteam(t, "ok")
+
+	sc, err := NewClient(context.Background(), "key",
+		WithHTTPClient(api.Client()),
+		WithAPIURL(api.URL+"/"),
+		WithStoreURL(" "+store.URL+" "),
+		WithUserAgent("kanbanchan-test"),
+		WithRateLimiter(nil),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %s", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		endpoint string
+		requests func() []request
+		wantURI  string
+	}{
+		{name: "api", baseURL: sc.apiURL, endpoint: "/ISteamApps/GetAppList/v0002/?format=json", requests: apiRequests, wantURI: "/ISteamApps/GetAppList/v0002/?format=json"},
+		{name: "store", baseURL: sc.storeURL, endpoint: "/api/appdetails?appids=620", requests: storeRequests, wantURI: "/api/appdetails?appids=620"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := sc.get(tt.baseURL, tt.endpoint)
+			if err != nil {
+				t.Fatalf("get() error = %s", err.Error())
+			}
+			if string(body) != "ok" {
+				t.Errorf("get() = %q, want %q", body, "ok")
+			}
+			got := tt.requests()
+			if len(got) != 1 {
+				t.Fatalf("server saw %d requests, want 1", len(got))
+			}
+			if got[0].URI != tt.wantURI {
+				t.Errorf("request URI = %q, want %q", got[0].URI, tt.wantURI)
+			}
+			if got[0].UserAgent != "kanbanchan-test" {
+				t.Errorf("request User-Agent = %q, want %q", got[0].UserAgent, "kanbanchan-test")
+			}
+		})
+	}
+}
+
+func TestClientOptionsHTTPClient(t *testing.T) {
+	server, requests := fakeSteam(t, "ok")
+
+	// The injected client's transport must carry the request, not http.DefaultClient's
+	transport := &countingTransport{next: server.Client().Transport}
+	sc, err := NewClient(context.Background(), "key",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithAPIURL(server.URL),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %s", err.Error())
+	}
+
+	_, err = sc.get(sc.apiURL, "/")
+	if err != nil {
+		t.Fatalf("get() error = %s", err.Error())
+	}
+	if transport.count() != 1 {
+		t.Errorf("transport carried %d requests, want 1", transport.count())
+	}
+	if got := requests(); len(got) != 1 || got[0].UserAgent != defaultUserAgent {
+		t.Errorf("requests = %v, want one with User-Agent %q", got, defaultUserAgent)
+	}
+}
+
+func TestClientOptionsIgnoreEmpty(t *testing.T) {
+	sc, err := NewClient(context.Background(), "key",
+		WithHTTPClient(nil),
+		WithAPIURL(" "),
+		WithStoreURL(""),
+		WithUserAgent(" "),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %s", err.Error())
+	}
+	if sc.httpClient != http.DefaultClient {
+		t.Errorf("httpClient = %v, want http.DefaultClient", sc.httpClient)
+	}
+	if sc.apiURL != steamAPIURL || sc.storeURL != steamURL || sc.userAgent != defaultUserAgent {
+		t.Errorf("apiURL, storeURL, userAgent = %q, %q, %q, want the defaults", sc.apiURL, sc.storeURL, sc.userAgent)
+	}
+}
+
+func TestGetAppCacheError(t *testing.T) {
+	server, _ := fakeSteam(t, `{"620":{"success":true,"data":{"name":"Portal 2","steam_appid":620}}}`)
+
+	var cacheErrs []error
+	sc, err := NewClient(context.Background(), "key",
+		WithStoreURL(server.URL),
+		WithRateLimiter(nil),
+		WithAppCache(failingAppCache{}),
+		WithCacheErrorHandler(func(err error) { cacheErrs = append(cacheErrs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %s", err.Error())
+	}
+
+	app, err := sc.GetApp("620")
+	if err != nil {
+		t.Fatalf("GetApp() error = %s", err.Error())
+	}
+	if app.Data.Name != "Portal 2" {
+		t.Errorf("GetApp() name = %q, want %q", app.Data.Name, "Portal 2")
+	}
+	if len(cacheErrs) != 1 {
+		t.Errorf("cache errors = %v, want 1", cacheErrs)
+	}
+}
+
+// failingAppCache is an AppCache that is always empty and can't be written
+type failingAppCache struct{}
+
+func (failingAppCache) Get(appID string) (*SteamApp, bool) { return nil, false }
+
+func (failingAppCache) Set(appID string, app *SteamApp, ttl time.Duration) error {
+	return errors.New("disk full")
+}
+
+func (failingAppCache) Delete(appID string) error { return nil }
+
+// countingTransport counts the requests passed through to next
+type countingTransport struct {
+	mu   sync.Mutex
+	n    int
+	next http.RoundTripper
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.mu.Lock()
+	ct.n++
+	ct.mu.Unlock()
+	return ct.next.RoundTrip(req)
+}
+
+func (ct *countingTransport) count() int {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.n
+}
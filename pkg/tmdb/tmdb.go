@@ -0,0 +1,279 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	tmdbAPIURL   = "https://api.themoviedb.org/3"
+	tmdbImageURL = "https://image.tmdb.org/t/p"
+
+	defaultUserAgent = "kanbanchan"
+)
+
+// TMDBClient contains authentication info for The Movie Database API
+type TMDBClient struct {
+	ctx        context.Context
+	apiKey     string
+	httpClient *http.Client
+	apiURL     string
+	imageURL   string
+	userAgent  string
+	language   string
+}
+
+// ClientOption configures optional settings on a TMDBClient
+type ClientOption func(*TMDBClient)
+
+// Genre defines a movie or TV genre
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CrewMember defines a person who worked on a movie or show
+type CrewMember struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Job        string `json:"job"`
+	Department string `json:"department"`
+}
+
+// Credits defines the crew of a movie or show
+type Credits struct {
+	Crew []CrewMember `json:"crew"`
+}
+
+// MovieResult defines a movie found by a search
+type MovieResult struct {
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title"`
+	ReleaseDate   string  `json:"release_date"` // YYYY-MM-DD, or empty when unknown
+	Overview      string  `json:"overview"`
+	PosterPath    string  `json:"poster_path"`
+	Popularity    float64 `json:"popularity"`
+}
+
+// Movie defines the details of a movie
+type Movie struct {
+	ID            int     `json:"id"`
+	IMDbID        string  `json:"imdb_id"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title"`
+	ReleaseDate   string  `json:"release_date"` // YYYY-MM-DD, or empty when unknown
+	Runtime       int     `json:"runtime"`      // minutes, 0 when unknown
+	Overview      string  `json:"overview"`
+	PosterPath    string  `json:"poster_path"`
+	Status        string  `json:"status"`
+	Genres        []Genre `json:"genres"`
+	Credits       Credits `json:"credits"`
+}
+
+// WatchProvider defines a service a title can be watched on
+type WatchProvider struct {
+	ID   int    `json:"provider_id"`
+	Name string `json:"provider_name"`
+}
+
+// RegionWatchProviders defines where a title can be watched in one region
+type RegionWatchProviders struct {
+	Link     string          `json:"link"`
+	Flatrate []WatchProvider `json:"flatrate"` // included in a subscription
+	Free     []WatchProvider `json:"free"`
+	Ads      []WatchProvider `json:"ads"`
+	Rent     []WatchProvider `json:"rent"`
+	Buy      []WatchProvider `json:"buy"`
+}
+
+// WatchProviders defines where a title can be watched, keyed by region code
+type WatchProviders struct {
+	ID      int                             `json:"id"`
+	Results map[string]RegionWatchProviders `json:"results"`
+}
+
+// StatusError is returned when TMDB responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected response status %s", e.Status)
+	}
+	return fmt.Sprintf("unexpected response status %s: %s", e.Status, e.Message)
+}
+
+// NewClient creates a new TMDB client authenticated with the supplied API
+// key. Both v3 API keys and v4 read access tokens are accepted
+func NewClient(ctx context.Context, apiKey string, opts ...ClientOption) (*TMDBClient, error) {
+	client := TMDBClient{
+		httpClient: http.DefaultClient,
+		apiURL:     tmdbAPIURL,
+		imageURL:   tmdbImageURL,
+		userAgent:  defaultUserAgent,
+	}
+	if ctx == nil {
+		client.ctx = context.Background()
+	} else {
+		client.ctx = ctx
+	}
+	key := strings.TrimSpace(apiKey)
+	if key == "" {
+		return nil, fmt.Errorf("empty apiKey provided")
+	}
+	client.apiKey = key
+	for _, opt := range opts {
+		opt(&client)
+	}
+	return &client, nil
+}
+
+// WithHTTPClient overrides the http.Client used for all requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(tc *TMDBClient) {
+		if httpClient != nil {
+			tc.httpClient = httpClient
+		}
+	}
+}
+
+// WithAPIURL overrides the base URL of the API (api.themoviedb.org/3), such
+// as to point the client at a local fake
+func WithAPIURL(apiURL string) ClientOption {
+	return func(tc *TMDBClient) {
+		if apiURL = strings.TrimRight(strings.TrimSpace(apiURL), "/"); apiURL != "" {
+			tc.apiURL = apiURL
+		}
+	}
+}
+
+// WithImageURL overrides the base URL images are served from (image.tmdb.org/t/p)
+func WithImageURL(imageURL string) ClientOption {
+	return func(tc *TMDBClient) {
+		if imageURL = strings.TrimRight(strings.TrimSpace(imageURL), "/"); imageURL != "" {
+			tc.imageURL = imageURL
+		}
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(tc *TMDBClient) {
+		if userAgent = strings.TrimSpace(userAgent); userAgent != "" {
+			tc.userAgent = userAgent
+		}
+	}
+}
+
+// WithLanguage requests titles and overviews in a language (e.g. "en-US")
+func WithLanguage(language string) ClientOption {
+	return func(tc *TMDBClient) {
+		tc.language = strings.TrimSpace(language)
+	}
+}
+
+// SearchMovies searches for movies by title, optionally narrowed to those
+// released in year when it isn't 0. Results are ordered by relevance
+func (tc *TMDBClient) SearchMovies(query string, year int) ([]MovieResult, error) {
+	params := url.Values{"query": {query}}
+	if year > 0 {
+		params.Set("year", strconv.Itoa(year))
+	}
+	var results struct {
+		Results []MovieResult `json:"results"`
+	}
+	err := tc.get("/search/movie", params, &results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search movies for \"%s\": %s", query, err.Error())
+	}
+	return results.Results, nil
+}
+
+// GetMovie retrieves the details and crew of a movie
+func (tc *TMDBClient) GetMovie(movieID int) (*Movie, error) {
+	var movie Movie
+	err := tc.get(fmt.Sprintf("/movie/%d", movieID), url.Values{"append_to_response": {"credits"}}, &movie)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie id %d: %s", movieID, err.Error())
+	}
+	return &movie, nil
+}
+
+// GetMovieWatchProviders retrieves where a movie can be watched in every region
+func (tc *TMDBClient) GetMovieWatchProviders(movieID int) (*WatchProviders, error) {
+	var providers WatchProviders
+	err := tc.get(fmt.Sprintf("/movie/%d/watch/providers", movieID), nil, &providers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watch providers for movie id %d: %s", movieID, err.Error())
+	}
+	return &providers, nil
+}
+
+// ImageURL returns the URL of an image path such as a poster at a size like
+// "w500" or "original", or an empty string when there's no image
+func (tc *TMDBClient) ImageURL(path string, size string) string {
+	if path == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s%s", tc.imageURL, size, path)
+}
+
+// get performs a GET request against the API and decodes the response into result
+func (tc *TMDBClient) get(endpoint string, params url.Values, result interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	if tc.language != "" {
+		params.Set("language", tc.language)
+	}
+	// v4 read access tokens are JWTs sent as a bearer token, v3 keys are a parameter
+	bearer := strings.HasPrefix(tc.apiKey, "eyJ")
+	if !bearer {
+		params.Set("api_key", tc.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(tc.ctx, http.MethodGet, fmt.Sprintf("%s%s?%s", tc.apiURL, endpoint, params.Encode()), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %s", err.Error())
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", tc.userAgent)
+	if bearer {
+		req.Header.Set("Authorization", "Bearer "+tc.apiKey)
+	}
+
+	resp, err := tc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %s", err.Error())
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			StatusMessage string `json:"status_message"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Message: apiErr.StatusMessage}
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %s", err.Error())
+	}
+	return nil
+}
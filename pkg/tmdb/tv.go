@@ -0,0 +1,99 @@
+package tmdb
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// TVResult defines a show found by a search
+type TVResult struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	OriginalName string  `json:"original_name"`
+	FirstAirDate string  `json:"first_air_date"` // YYYY-MM-DD, or empty when unknown
+	Overview     string  `json:"overview"`
+	PosterPath   string  `json:"poster_path"`
+	Popularity   float64 `json:"popularity"`
+}
+
+// Network defines a network or service a show airs on
+type Network struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Season defines a season of a show. Season 0 holds specials
+type Season struct {
+	ID           int    `json:"id"`
+	SeasonNumber int    `json:"season_number"`
+	Name         string `json:"name"`
+	EpisodeCount int    `json:"episode_count"`
+	AirDate      string `json:"air_date"` // YYYY-MM-DD, or empty when unannounced
+}
+
+// Episode defines a single episode of a show
+type Episode struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	AirDate       string `json:"air_date"` // YYYY-MM-DD, or empty when unannounced
+}
+
+// TV defines the details of a show
+type TV struct {
+	ID               int       `json:"id"`
+	Name             string    `json:"name"`
+	OriginalName     string    `json:"original_name"`
+	Status           string    `json:"status"` // Returning Series, Ended, Canceled, In Production or Planned
+	InProduction     bool      `json:"in_production"`
+	FirstAirDate     string    `json:"first_air_date"`
+	LastAirDate      string    `json:"last_air_date"`
+	NumberOfSeasons  int       `json:"number_of_seasons"`
+	NumberOfEpisodes int       `json:"number_of_episodes"`
+	Overview         string    `json:"overview"`
+	PosterPath       string    `json:"poster_path"`
+	Genres           []Genre   `json:"genres"`
+	Networks         []Network `json:"networks"`
+	Seasons          []Season  `json:"seasons"`
+	LastEpisodeToAir *Episode  `json:"last_episode_to_air"`
+	NextEpisodeToAir *Episode  `json:"next_episode_to_air"`
+}
+
+// SearchTV searches for shows by name, optionally narrowed to those that
+// first aired in year when it isn't 0. Results are ordered by relevance
+func (tc *TMDBClient) SearchTV(query string, year int) ([]TVResult, error) {
+	params := url.Values{"query": {query}}
+	if year > 0 {
+		params.Set("first_air_date_year", strconv.Itoa(year))
+	}
+	var results struct {
+		Results []TVResult `json:"results"`
+	}
+	err := tc.get("/search/tv", params, &results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tv for \"%s\": %s", query, err.Error())
+	}
+	return results.Results, nil
+}
+
+// GetTV retrieves the details, seasons and latest episodes of a show
+func (tc *TMDBClient) GetTV(tvID int) (*TV, error) {
+	var tv TV
+	err := tc.get(fmt.Sprintf("/tv/%d", tvID), nil, &tv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tv id %d: %s", tvID, err.Error())
+	}
+	return &tv, nil
+}
+
+// GetTVWatchProviders retrieves where a show can be watched in every region
+func (tc *TMDBClient) GetTVWatchProviders(tvID int) (*WatchProviders, error) {
+	var providers WatchProviders
+	err := tc.get(fmt.Sprintf("/tv/%d/watch/providers", tvID), nil, &providers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watch providers for tv id %d: %s", tvID, err.Error())
+	}
+	return &providers, nil
+}
@@ -0,0 +1,273 @@
+package vdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Binary KeyValues type markers
+const (
+	binaryTypeSection byte = 0x00
+	binaryTypeString  byte = 0x01
+	binaryTypeInt32   byte = 0x02
+	binaryTypeFloat32 byte = 0x03
+	binaryTypePointer byte = 0x04
+	binaryTypeWString byte = 0x05
+	binaryTypeColor   byte = 0x06
+	binaryTypeUint64  byte = 0x07
+	binaryTypeEnd     byte = 0x08
+	binaryTypeInt64   byte = 0x0A
+	binaryTypeEndAlt  byte = 0x0B
+	appInfoMagicV27        = 0x07564427
+	appInfoMagicV28        = 0x07564428
+	appInfoMagicV29        = 0x07564429
+)
+
+// AppInfo is a single app read from the Steam client's appinfo.vdf
+type AppInfo struct {
+	AppID        uint32
+	InfoState    uint32
+	LastUpdated  time.Time
+	PICSToken    uint64
+	ChangeNumber uint32
+	Data         map[string]interface{}
+}
+
+// DecodeBinary reads binary KeyValues, as used by shortcuts.vdf, from r
+func DecodeBinary(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBinary(data)
+}
+
+// ParseBinary parses binary KeyValues, as used by shortcuts.vdf
+func ParseBinary(data []byte) (map[string]interface{}, error) {
+	br := binaryReader{r: bytes.NewReader(data)}
+	return br.section(false)
+}
+
+// ReadAppInfo reads every app from the Steam client's appinfo.vdf. Versions
+// 27, 28 and 29 of the format are supported
+func ReadAppInfo(r io.Reader) ([]AppInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader(data)
+
+	var header struct {
+		Magic    uint32
+		Universe uint32
+	}
+	err = binary.Read(reader, binary.LittleEndian, &header)
+	if err != nil {
+		return nil, fmt.Errorf("vdf: failed to read appinfo header: %s", err.Error())
+	}
+	if header.Magic != appInfoMagicV27 && header.Magic != appInfoMagicV28 && header.Magic != appInfoMagicV29 {
+		return nil, fmt.Errorf("vdf: unsupported appinfo version %#x", header.Magic)
+	}
+
+	br := binaryReader{r: reader}
+	if header.Magic == appInfoMagicV29 {
+		// Keys are indexes into a string table stored at the end of the file
+		var tableOffset int64
+		err = binary.Read(reader, binary.LittleEndian, &tableOffset)
+		if err != nil {
+			return nil, fmt.Errorf("vdf: failed to read appinfo string table offset: %s", err.Error())
+		}
+		if tableOffset < 0 || tableOffset > int64(len(data)) {
+			return nil, fmt.Errorf("vdf: invalid appinfo string table offset %d", tableOffset)
+		}
+		br.strings, err = readStringTable(data[tableOffset:])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var apps []AppInfo
+	for {
+		var appID uint32
+		err = binary.Read(reader, binary.LittleEndian, &appID)
+		if err != nil {
+			return nil, fmt.Errorf("vdf: failed to read app id: %s", err.Error())
+		}
+		if appID == 0 { // end of apps
+			return apps, nil
+		}
+
+		var entry struct {
+			Size         uint32
+			InfoState    uint32
+			LastUpdated  uint32
+			PICSToken    uint64
+			SHA1         [20]byte
+			ChangeNumber uint32
+		}
+		err = binary.Read(reader, binary.LittleEndian, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("vdf: failed to read app id %d: %s", appID, err.Error())
+		}
+		if header.Magic != appInfoMagicV27 {
+			var binarySHA1 [20]byte
+			err = binary.Read(reader, binary.LittleEndian, &binarySHA1)
+			if err != nil {
+				return nil, fmt.Errorf("vdf: failed to read app id %d: %s", appID, err.Error())
+			}
+		}
+
+		// Another app or the end of apps always follows, so running out of
+		// data before the end marker means the file was cut short
+		kv, err := br.section(true)
+		if err != nil {
+			return nil, fmt.Errorf("vdf: failed to read app id %d: %s", appID, err.Error())
+		}
+		apps = append(apps, AppInfo{
+			AppID:        appID,
+			InfoState:    entry.InfoState,
+			LastUpdated:  time.Unix(int64(entry.LastUpdated), 0),
+			PICSToken:    entry.PICSToken,
+			ChangeNumber: entry.ChangeNumber,
+			Data:         kv,
+		})
+	}
+}
+
+func readStringTable(data []byte) ([]string, error) {
+	reader := bytes.NewReader(data)
+	var count uint32
+	err := binary.Read(reader, binary.LittleEndian, &count)
+	if err != nil {
+		return nil, fmt.Errorf("vdf: failed to read string table: %s", err.Error())
+	}
+	br := binaryReader{r: reader}
+	table := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		s, err := br.cstring()
+		if err != nil {
+			return nil, fmt.Errorf("vdf: failed to read string table: %s", err.Error())
+		}
+		table = append(table, s)
+	}
+	return table, nil
+}
+
+type binaryReader struct {
+	r       *bytes.Reader
+	strings []string // key table used by appinfo.vdf v29
+}
+
+// section reads key/value pairs until the end of the current section. Only a
+// top-level section may end with the data instead of an end marker, so
+// truncated nested sections fail with io.ErrUnexpectedEOF
+func (br *binaryReader) section(nested bool) (map[string]interface{}, error) {
+	kv := make(map[string]interface{})
+	for {
+		valueType, err := br.r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			if nested {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return kv, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if valueType == binaryTypeEnd || valueType == binaryTypeEndAlt {
+			return kv, nil
+		}
+
+		key, err := br.key()
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		switch valueType {
+		case binaryTypeSection:
+			value, err = br.section(true)
+		case binaryTypeString:
+			value, err = br.cstring()
+		case binaryTypeInt32, binaryTypePointer, binaryTypeColor:
+			var n int32
+			err = binary.Read(br.r, binary.LittleEndian, &n)
+			value = n
+		case binaryTypeFloat32:
+			var bits uint32
+			err = binary.Read(br.r, binary.LittleEndian, &bits)
+			value = math.Float32frombits(bits)
+		case binaryTypeUint64:
+			var n uint64
+			err = binary.Read(br.r, binary.LittleEndian, &n)
+			value = n
+		case binaryTypeInt64:
+			var n int64
+			err = binary.Read(br.r, binary.LittleEndian, &n)
+			value = n
+		case binaryTypeWString:
+			value, err = br.wstring()
+		default:
+			return nil, fmt.Errorf("vdf: unknown binary type %#x for key %s", valueType, key)
+		}
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("vdf: failed to read key %s: %s", key, err.Error())
+		}
+		kv[key] = value
+	}
+}
+
+func (br *binaryReader) key() (string, error) {
+	if br.strings == nil {
+		return br.cstring()
+	}
+	var index uint32
+	err := binary.Read(br.r, binary.LittleEndian, &index)
+	if err != nil {
+		return "", err
+	}
+	if int(index) >= len(br.strings) {
+		return "", fmt.Errorf("vdf: string table index %d out of range", index)
+	}
+	return br.strings[index], nil
+}
+
+// cstring reads a null-terminated string
+func (br *binaryReader) cstring() (string, error) {
+	var buf bytes.Buffer
+	for {
+		b, err := br.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b == 0 {
+			return buf.String(), nil
+		}
+		buf.WriteByte(b)
+	}
+}
+
+// wstring reads a null-terminated UTF-16 string
+func (br *binaryReader) wstring() (string, error) {
+	var runes []rune
+	for {
+		var unit uint16
+		err := binary.Read(br.r, binary.LittleEndian, &unit)
+		if err != nil {
+			return "", err
+		}
+		if unit == 0 {
+			return string(runes), nil
+		}
+		runes = append(runes, rune(unit))
+	}
+}
@@ -0,0 +1,182 @@
+package vdf
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeBinary(t *testing.T) {
+	file, err := os.Open("testdata/shortcuts.vdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	got, err := DecodeBinary(file)
+	if err != nil {
+		t.Fatalf("DecodeBinary() error = %s", err.Error())
+	}
+
+	want := map[string]interface{}{
+		"shortcuts": map[string]interface{}{
+			"0": map[string]interface{}{
+				"appid":        int32(-1234567890),
+				"AppName":      "Celeste \"Classic\"",
+				"Exe":          "\"/usr/bin/celeste\"",
+				"StartDir":     "/usr/bin/",
+				"LastPlayTime": int32(1700000000),
+				"tags":         map[string]interface{}{"0": "Platformer", "1": "Favorites"},
+			},
+			"1": map[string]interface{}{
+				"appid":   int32(42),
+				"AppName": "Émulateur",
+				"Size":    uint64(1 << 40),
+				"Offset":  int64(-5),
+				"Scale":   float32(1.5),
+				"Title":   "ゲーム",
+				"tags":    map[string]interface{}{},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeBinary() = %v, want %v", got, want)
+	}
+}
+
+func TestParseBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: []byte{},
+			want:  map[string]interface{}{},
+		},
+		{
+			name:  "string",
+			input: []byte("\x01name\x00Portal\x00\x08"),
+			want:  map[string]interface{}{"name": "Portal"},
+		},
+		{
+			name:  "pointer and color read as int32",
+			input: []byte("\x04p\x00\x01\x00\x00\x00\x06c\x00\xff\xff\xff\xff\x08"),
+			want:  map[string]interface{}{"p": int32(1), "c": int32(-1)},
+		},
+		{
+			name:  "alternate end marker",
+			input: []byte("\x00s\x00\x01k\x00v\x00\x0b\x08"),
+			want:  map[string]interface{}{"s": map[string]interface{}{"k": "v"}},
+		},
+		{
+			name:  "missing end marker",
+			input: []byte("\x01k\x00v\x00"),
+			want:  map[string]interface{}{"k": "v"},
+		},
+		{
+			name:    "missing nested end marker",
+			input:   []byte("\x00s\x00\x01k\x00v\x00"),
+			wantErr: true,
+		},
+		{
+			name:    "truncated nested section",
+			input:   []byte("\x00shortcuts\x00\x000\x00\x01AppName\x00Celeste\x00\x08"),
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			input:   []byte("\x09k\x00\x08"),
+			wantErr: true,
+		},
+		{
+			name:    "truncated int32",
+			input:   []byte("\x02k\x00\x01\x00"),
+			wantErr: true,
+		},
+		{
+			name:    "unterminated string",
+			input:   []byte("\x01k\x00value"),
+			wantErr: true,
+		},
+		{
+			name:    "unterminated key",
+			input:   []byte("\x01key"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBinary(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseBinary() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBinary() error = %s", err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadAppInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		names map[uint32]string
+	}{
+		{name: "version 28", path: "testdata/appinfo_v28.vdf", names: map[uint32]string{10: "Counter-Strike", 70: "Half-Life"}},
+		{name: "version 29 with a string table", path: "testdata/appinfo_v29.vdf", names: map[uint32]string{10: "Counter-Strike"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Open(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			apps, err := ReadAppInfo(file)
+			if err != nil {
+				t.Fatalf("ReadAppInfo() error = %s", err.Error())
+			}
+			if len(apps) != len(tt.names) {
+				t.Fatalf("ReadAppInfo() returned %d apps, want %d", len(apps), len(tt.names))
+			}
+			for _, app := range apps {
+				if got := String(Lookup(app.Data, "appinfo", "common"), "name"); got != tt.names[app.AppID] {
+					t.Errorf("app id %d name = %q, want %q", app.AppID, got, tt.names[app.AppID])
+				}
+				if !app.LastUpdated.Equal(time.Unix(1700000000, 0)) || app.PICSToken != 99 || app.ChangeNumber != 7 {
+					t.Errorf("app id %d header = %+v", app.AppID, app)
+				}
+			}
+		})
+	}
+
+	_, err := ReadAppInfo(bytes.NewReader([]byte("\x27\x44\x56\x06\x01\x00\x00\x00")))
+	if err == nil {
+		t.Errorf("ReadAppInfo() of an unsupported version should fail")
+	}
+
+	// Cut partway through the first app's data, after its 76 byte header
+	data, err := os.ReadFile("testdata/appinfo_v28.vdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadAppInfo(bytes.NewReader(data[:80]))
+	if err == nil || !strings.Contains(err.Error(), io.ErrUnexpectedEOF.Error()) {
+		t.Errorf("ReadAppInfo() of a truncated file error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
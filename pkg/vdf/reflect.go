@@ -0,0 +1,241 @@
+package vdf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalMap stores decoded KeyValues in v, which must be a pointer to a
+// struct or map. Struct fields are matched by their `vdf:"name"` tag or,
+// without one, by field name, both case-insensitively. A tag of "-" skips the
+// field. Sections whose keys are "0", "1", ... can be stored in slices
+func UnmarshalMap(kv map[string]interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("vdf: unmarshal target must be a non-nil pointer, got %T", v)
+	}
+	return assign(rv.Elem(), kv, "")
+}
+
+// assign stores a decoded value in dst, using path to describe errors
+func assign(dst reflect.Value, value interface{}, path string) error {
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), value, path)
+	}
+
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		dst.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	section, isSection := value.(map[string]interface{})
+	switch dst.Kind() {
+	case reflect.Struct:
+		if !isSection {
+			return fmt.Errorf("vdf: cannot store value in struct at %s", path)
+		}
+		return assignStruct(dst, section, path)
+	case reflect.Map:
+		if !isSection {
+			return fmt.Errorf("vdf: cannot store value in map at %s", path)
+		}
+		if dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("vdf: map keys must be strings at %s", path)
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for key, child := range section {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			err := assign(elem, child, path+"."+key)
+			if err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice:
+		if !isSection {
+			return fmt.Errorf("vdf: cannot store value in slice at %s", path)
+		}
+		keys := sortedIndexKeys(section)
+		slice := reflect.MakeSlice(dst.Type(), len(keys), len(keys))
+		for i, key := range keys {
+			err := assign(slice.Index(i), section[key], path+"."+key)
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	}
+
+	if isSection {
+		return fmt.Errorf("vdf: cannot store section in %s at %s", dst.Type(), path)
+	}
+	return assignScalar(dst, value, path)
+}
+
+func assignStruct(dst reflect.Value, section map[string]interface{}, path string) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		value, ok := lookupKey(section, name)
+		if !ok {
+			continue
+		}
+		err := assign(dst.Field(i), value, path+"."+name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assignScalar(dst reflect.Value, value interface{}, path string) error {
+	s := fmt.Sprint(value)
+	var err error
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, dst.Type().Bits())
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, dst.Type().Bits())
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, dst.Type().Bits())
+		dst.SetFloat(f)
+	default:
+		return fmt.Errorf("vdf: unsupported type %s at %s", dst.Type(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("vdf: invalid %s value %q at %s", dst.Kind(), s, path)
+	}
+	return nil
+}
+
+// toMap converts a struct or map into KeyValues for encoding
+func toMap(v interface{}) (map[string]interface{}, error) {
+	encoded, err := encodeValue(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	kv, ok := encoded.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("vdf: can only encode structs and maps, got %T", v)
+	}
+	return kv, nil
+}
+
+func encodeValue(v reflect.Value) (interface{}, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		kv := make(map[string]interface{})
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := fieldName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			encoded, err := encodeValue(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			if encoded != nil {
+				kv[name] = encoded
+			}
+		}
+		return kv, nil
+	case reflect.Map:
+		kv := make(map[string]interface{})
+		iter := v.MapRange()
+		for iter.Next() {
+			encoded, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			if encoded != nil {
+				kv[fmt.Sprint(iter.Key().Interface())] = encoded
+			}
+		}
+		return kv, nil
+	case reflect.Slice, reflect.Array:
+		kv := make(map[string]interface{})
+		for i := 0; i < v.Len(); i++ {
+			encoded, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if encoded != nil {
+				kv[strconv.Itoa(i)] = encoded
+			}
+		}
+		return kv, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), nil
+	}
+	return nil, fmt.Errorf("vdf: unsupported type %s", v.Type())
+}
+
+func fieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("vdf")
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// sortedIndexKeys orders section keys numerically when they're indexes, falling
+// back to lexical order for anything else
+func sortedIndexKeys(section map[string]interface{}) []string {
+	keys := make([]string, 0, len(section))
+	for key := range section {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		na, errA := strconv.Atoi(keys[a])
+		nb, errB := strconv.Atoi(keys[b])
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return keys[a] < keys[b]
+	})
+	return keys
+}
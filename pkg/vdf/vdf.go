@@ -0,0 +1,257 @@
+// Package vdf reads and writes Valve's KeyValues format, used by the Steam
+// client for files such as libraryfolders.vdf, appmanifest_*.acf and
+// localconfig.vdf, along with the binary variant used by shortcuts.vdf and
+// appinfo.vdf.
+//
+// Decoded KeyValues are nested map[string]interface{} values whose leaves are
+// strings for the text format, or strings, int32, uint64, int64 and float32
+// for the binary format.
+package vdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Parse parses text KeyValues into nested maps. Comments and platform
+// conditionals such as [$WIN32] are ignored, and the last of any duplicate
+// keys wins
+func Parse(data []byte) (map[string]interface{}, error) {
+	p := parser{data: []rune(string(data))}
+	root, err := p.parseSection(false)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Decode reads and parses text KeyValues from r
+func Decode(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Unmarshal parses text KeyValues into v, which must be a pointer to a struct or map
+func Unmarshal(data []byte, v interface{}) error {
+	kv, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	return UnmarshalMap(kv, v)
+}
+
+// Marshal encodes v, a struct or map, as text KeyValues
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := NewEncoder(&buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encoder writes text KeyValues to an output stream
+type Encoder struct {
+	w      io.Writer
+	indent string
+}
+
+// NewEncoder returns an Encoder writing to w, indenting nested sections with tabs
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, indent: "\t"}
+}
+
+// Encode writes v, a struct or map, as text KeyValues. Map keys are written in sorted order
+func (e *Encoder) Encode(v interface{}) error {
+	kv, err := toMap(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writeSection(&buf, kv, 0, e.indent)
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+// Lookup walks nested sections by key, matching keys case-insensitively since
+// Steam isn't consistent about their casing. It returns nil when any section
+// along the path is missing
+func Lookup(kv map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, key := range keys {
+		value, ok := lookupKey(kv, key)
+		if !ok {
+			return nil
+		}
+		next, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		kv = next
+	}
+	return kv
+}
+
+// String returns the string value of key in kv, matched case-insensitively
+func String(kv map[string]interface{}, key string) string {
+	value, ok := lookupKey(kv, key)
+	if !ok {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if _, ok := value.(map[string]interface{}); ok {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func lookupKey(kv map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := kv[key]; ok {
+		return value, true
+	}
+	for name, value := range kv {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func writeSection(buf *bytes.Buffer, kv map[string]interface{}, depth int, indent string) {
+	keys := make([]string, 0, len(kv))
+	for key := range kv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	prefix := strings.Repeat(indent, depth)
+	for _, key := range keys {
+		if child, ok := kv[key].(map[string]interface{}); ok {
+			fmt.Fprintf(buf, "%s%s\n%s{\n", prefix, quote(key), prefix)
+			writeSection(buf, child, depth+1, indent)
+			fmt.Fprintf(buf, "%s}\n", prefix)
+			continue
+		}
+		fmt.Fprintf(buf, "%s%s\t\t%s\n", prefix, quote(key), quote(fmt.Sprint(kv[key])))
+	}
+}
+
+func quote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+type parser struct {
+	data []rune
+	pos  int
+}
+
+func (p *parser) parseSection(nested bool) (map[string]interface{}, error) {
+	section := make(map[string]interface{})
+	for {
+		token, quoted, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case token == "" && !quoted:
+			if nested {
+				return nil, fmt.Errorf("vdf: unexpected end of input inside section")
+			}
+			return section, nil
+		case token == "}" && !quoted:
+			if !nested {
+				return nil, fmt.Errorf("vdf: unexpected } at offset %d", p.pos)
+			}
+			return section, nil
+		case token == "{" && !quoted:
+			return nil, fmt.Errorf("vdf: unexpected { at offset %d", p.pos)
+		}
+
+		value, valueQuoted, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if value == "{" && !valueQuoted {
+			child, err := p.parseSection(true)
+			if err != nil {
+				return nil, err
+			}
+			section[token] = child
+		} else if (value == "" || value == "}") && !valueQuoted {
+			return nil, fmt.Errorf("vdf: missing value for key %s", token)
+		} else {
+			section[token] = value
+		}
+	}
+}
+
+// next returns the next token and whether it was quoted, skipping whitespace,
+// comments and platform conditionals
+func (p *parser) next() (string, bool, error) {
+	for p.pos < len(p.data) {
+		r := p.data[p.pos]
+		switch {
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\uFEFF':
+			p.pos++
+		case r == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		case r == '[':
+			for p.pos < len(p.data) && p.data[p.pos] != ']' {
+				p.pos++
+			}
+			p.pos++
+		case r == '{' || r == '}':
+			p.pos++
+			return string(r), false, nil
+		case r == '"':
+			return p.quoted()
+		default:
+			start := p.pos
+			for p.pos < len(p.data) && !strings.ContainsRune(" \t\r\n{}\"", p.data[p.pos]) {
+				p.pos++
+			}
+			return string(p.data[start:p.pos]), false, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (p *parser) quoted() (string, bool, error) {
+	var builder strings.Builder
+	p.pos++ // opening quote
+	for p.pos < len(p.data) {
+		r := p.data[p.pos]
+		p.pos++
+		switch r {
+		case '"':
+			return builder.String(), true, nil
+		case '\\':
+			if p.pos >= len(p.data) {
+				continue
+			}
+			escaped := p.data[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				builder.WriteRune('\n')
+			case 't':
+				builder.WriteRune('\t')
+			default:
+				builder.WriteRune(escaped)
+			}
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return "", false, fmt.Errorf("vdf: unterminated string")
+}
@@ -0,0 +1,265 @@
+package vdf
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  map[string]interface{}{},
+		},
+		{
+			name:  "quoted key and value",
+			input: `"name" "Portal 2"`,
+			want:  map[string]interface{}{"name": "Portal 2"},
+		},
+		{
+			name:  "unquoted tokens",
+			input: "appid 620\nname Portal",
+			want:  map[string]interface{}{"appid": "620", "name": "Portal"},
+		},
+		{
+			name:  "escapes",
+			input: `"path" "C:\\Games\t\"Portal\"\n"`,
+			want:  map[string]interface{}{"path": "C:\\Games\t\"Portal\"\n"},
+		},
+		{
+			name:  "unknown escape keeps the character",
+			input: `"key" "a\qb"`,
+			want:  map[string]interface{}{"key": "aqb"},
+		},
+		{
+			name:  "nested sections",
+			input: "\"a\"\n{\n\t\"b\"\n\t{\n\t\t\"c\" \"1\"\n\t}\n\t\"d\" \"2\"\n}",
+			want: map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": map[string]interface{}{"c": "1"},
+					"d": "2",
+				},
+			},
+		},
+		{
+			name:  "empty section",
+			input: `"tags" {}`,
+			want:  map[string]interface{}{"tags": map[string]interface{}{}},
+		},
+		{
+			name:  "comments and conditionals",
+			input: "// header\n\"a\" \"1\" [$WIN32]\n\"b\" \"2\" // trailing\n",
+			want:  map[string]interface{}{"a": "1", "b": "2"},
+		},
+		{
+			name:  "byte order mark",
+			input: "\uFEFF\"a\" \"1\"",
+			want:  map[string]interface{}{"a": "1"},
+		},
+		{
+			name:  "last duplicate key wins",
+			input: `"a" "1" "a" "2"`,
+			want:  map[string]interface{}{"a": "2"},
+		},
+		{
+			name:  "quoted braces are values",
+			input: `"open" "{" "close" "}"`,
+			want:  map[string]interface{}{"open": "{", "close": "}"},
+		},
+		{
+			name:    "unterminated string",
+			input:   `"a" "1`,
+			wantErr: true,
+		},
+		{
+			name:    "unclosed section",
+			input:   `"a" { "b" "1"`,
+			wantErr: true,
+		},
+		{
+			name:    "unexpected closing brace",
+			input:   `"a" "1" }`,
+			wantErr: true,
+		},
+		{
+			name:    "unexpected opening brace",
+			input:   `{ "a" "1" }`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			input:   `"a"`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value before closing brace",
+			input:   `"a" { "b" }`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %s", err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	fileContent, err := os.ReadFile("testdata/localconfig.vdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := Parse(fileContent)
+	if err != nil {
+		t.Fatalf("Parse() error = %s", err.Error())
+	}
+
+	apps := Lookup(root, "userlocalconfigstore", "software", "valve", "steam", "apps")
+	tests := []struct {
+		name string
+		kv   map[string]interface{}
+		key  string
+		want string
+	}{
+		{name: "playtime", kv: Lookup(apps, "400"), key: "Playtime", want: "125"},
+		{name: "case-insensitive key", kv: Lookup(apps, "400"), key: "playtime2WKS", want: "30"},
+		{name: "escaped value", kv: Lookup(apps, "620"), key: "LaunchOptions", want: "-novid \"+exec autoexec.cfg\"\tC:\\Games\\Portal 2"},
+		{name: "value before conditional", kv: Lookup(apps, "620"), key: "Playtime", want: "0"},
+		{name: "unquoted section", kv: Lookup(root, "UserLocalConfigStore", "friends"), key: "PersonaName", want: "kanban chan"},
+		{name: "missing key", kv: Lookup(apps, "400"), key: "LaunchOptions", want: ""},
+		{name: "section is not a string", kv: apps, key: "400", want: ""},
+		{name: "missing section", kv: Lookup(apps, "730"), key: "Playtime", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.kv, tt.key); got != tt.want {
+				t.Errorf("String(%s) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type manifest struct {
+		AppState struct {
+			AppID      string            `vdf:"appid"`
+			StateFlags int               `vdf:"StateFlags"`
+			SizeOnDisk int64             `vdf:"SizeOnDisk"`
+			Scale      float64           `vdf:"scale"`
+			Updating   bool              `vdf:"updating"`
+			Depots     map[string]string `vdf:"InstalledDepots"`
+			Branches   []string          `vdf:"branches"`
+			Skipped    string            `vdf:"-"`
+			Name       string
+		} `vdf:"AppState"`
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name: "every kind of field",
+			input: `"AppState" {
+				"appid" "620" "StateFlags" "4" "SizeOnDisk" "12884901888" "scale" "1.5" "updating" "1"
+				"InstalledDepots" { "621" "123" }
+				"branches" { "1" "beta" "0" "public" "10" "staging" }
+				"-" "skipped" "name" "Portal 2" "unknown" "ignored"
+			}`,
+		},
+		{name: "invalid integer", input: `"AppState" { "StateFlags" "four" }`, wantErr: true},
+		{name: "section in a scalar", input: `"AppState" { "appid" { } }`, wantErr: true},
+		{name: "value in a struct", input: `"AppState" "620"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got manifest
+			err := Unmarshal([]byte(tt.input), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %s", err.Error())
+			}
+			state := got.AppState
+			if state.AppID != "620" || state.StateFlags != 4 || state.SizeOnDisk != 12884901888 || state.Scale != 1.5 || !state.Updating {
+				t.Errorf("Unmarshal() scalars = %+v", state)
+			}
+			if !reflect.DeepEqual(state.Depots, map[string]string{"621": "123"}) {
+				t.Errorf("Unmarshal() map = %v", state.Depots)
+			}
+			if !reflect.DeepEqual(state.Branches, []string{"public", "beta", "staging"}) {
+				t.Errorf("Unmarshal() slice = %v", state.Branches)
+			}
+			if state.Skipped != "" || state.Name != "Portal 2" {
+				t.Errorf("Unmarshal() names = %+v", state)
+			}
+		})
+	}
+
+	err := Unmarshal([]byte(`"a" "1"`), manifest{})
+	if err == nil {
+		t.Errorf("Unmarshal() into a non-pointer should fail")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	input := map[string]interface{}{
+		"root": map[string]interface{}{
+			"path":  "C:\\Games\t\"Portal\"\n",
+			"count": 3,
+			"flag":  true,
+			"list":  []string{"a", "b"},
+		},
+	}
+	encoded, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal() error = %s", err.Error())
+	}
+	got, err := Parse(encoded)
+	if err != nil {
+		t.Fatalf("Parse() of marshalled output error = %s\n%s", err.Error(), encoded)
+	}
+	want := map[string]interface{}{
+		"root": map[string]interface{}{
+			"path":  "C:\\Games\t\"Portal\"\n",
+			"count": "3",
+			"flag":  "1",
+			"list":  map[string]interface{}{"0": "a", "1": "b"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(Marshal()) = %v, want %v", got, want)
+	}
+
+	_, err = Marshal("not a map")
+	if err == nil {
+		t.Errorf("Marshal() of a string should fail")
+	}
+}